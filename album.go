@@ -0,0 +1,135 @@
+package telebot
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultAlbumWindow used to wait for the rest of media group messages.
+const DefaultAlbumWindow = time.Second
+
+// AlbumHandlerFunc handles messages of a media group ordered by message
+// identifier.
+type AlbumHandlerFunc func(ctx context.Context, msgs []*Message) error
+
+// album represents media group messages waiting to be delivered.
+type album struct {
+	ctx   context.Context
+	msgs  []*Message
+	acks  []func()
+	timer *time.Timer
+}
+
+// AlbumCollector implements Handler that buffers new messages and channel
+// posts sharing the same media group and delivers them together to the album
+// handler once no more messages arrive within the window. Other updates are
+// passed to the next handler unchanged. Buffered updates are acknowledged only
+// after the album is delivered. The album handler runs with a context detached
+// from the cancellation of the last buffered update. ErrorHandler receives
+// errors from the album handler like Bot.ErrorHandler.
+type AlbumCollector struct {
+	ErrorHandler func(err error)
+	h            Handler
+	fn           AlbumHandlerFunc
+	window       time.Duration
+	mu           sync.Mutex
+	albums       map[string]*album
+	wg           sync.WaitGroup
+}
+
+// NewAlbumCollector creates new album collector delivering media groups to fn
+// and other updates to h. If window is not positive, it will be set to
+// DefaultAlbumWindow.
+func NewAlbumCollector(h Handler, fn AlbumHandlerFunc, window time.Duration) *AlbumCollector {
+	if window <= 0 {
+		window = DefaultAlbumWindow
+	}
+	return &AlbumCollector{
+		h:      h,
+		fn:     fn,
+		window: window,
+		albums: make(map[string]*album),
+	}
+}
+
+// Albums creates middleware that collects media groups with AlbumCollector.
+// The collector is returned so it can be flushed before shutdown. Apply the
+// middleware to only one handler.
+func Albums(fn AlbumHandlerFunc, window time.Duration) (Middleware, *AlbumCollector) {
+	a := NewAlbumCollector(nil, fn, window)
+	return func(h Handler) Handler {
+		a.h = h
+		return a
+	}, a
+}
+
+// ServeUpdate implements the Handler interface.
+func (a *AlbumCollector) ServeUpdate(ctx context.Context, upd *Update) error {
+	msg := upd.Message
+	if msg == nil {
+		msg = upd.ChannelPost
+	}
+	if msg == nil || len(msg.MediaGroupID) == 0 {
+		return a.h.ServeUpdate(ctx, upd)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	al, ok := a.albums[msg.MediaGroupID]
+	if !ok {
+		al = &album{}
+		a.albums[msg.MediaGroupID] = al
+		a.wg.Add(1)
+		id, pending := msg.MediaGroupID, al
+		al.timer = time.AfterFunc(a.window, func() {
+			a.deliver(id, pending)
+		})
+	} else {
+		// Wait for another window after the latest message
+		al.timer.Reset(a.window)
+	}
+	al.ctx = detach(ctx)
+	al.msgs = append(al.msgs, msg)
+	al.acks = append(al.acks, DeferAck(ctx))
+	return nil
+}
+
+// deliver passes the media group to the album handler if it is still
+// buffered.
+func (a *AlbumCollector) deliver(id string, al *album) {
+	a.mu.Lock()
+	if a.albums[id] != al {
+		a.mu.Unlock()
+		return
+	}
+	delete(a.albums, id)
+	a.mu.Unlock()
+	defer a.wg.Done()
+	sort.SliceStable(al.msgs, func(i, j int) bool {
+		return al.msgs[i].ID < al.msgs[j].ID
+	})
+	if err := a.fn(al.ctx, al.msgs); err != nil {
+		reportError(a.ErrorHandler, err)
+	}
+	for _, ack := range al.acks {
+		ack()
+	}
+}
+
+// Flush delivers all buffered media groups immediately and waits until they
+// are handled. Call it before shutdown to avoid losing buffered messages.
+func (a *AlbumCollector) Flush() {
+	a.mu.Lock()
+	pending := make(map[string]*album)
+	for id, al := range a.albums {
+		if al.timer.Stop() {
+			pending[id] = al
+		}
+	}
+	a.mu.Unlock()
+	for id, al := range pending {
+		a.deliver(id, al)
+	}
+	a.wg.Wait()
+}
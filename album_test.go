@@ -0,0 +1,108 @@
+package telebot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adzil/telebot"
+	"github.com/adzil/telebot/telebottest"
+)
+
+func newAlbumUpdate(msgID int64, group string) *telebot.Update {
+	return &telebot.Update{Message: &telebot.Message{
+		ID:           msgID,
+		Chat:         &telebot.Chat{ID: 1, Type: telebot.PrivateChat},
+		MediaGroupID: group,
+	}}
+}
+
+func TestAlbumsPoll(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &telebot.MemoryOffsetStore{}
+	bot.Offsets = store
+	// Media group messages may arrive out of order
+	for _, id := range []int64{12, 10, 11} {
+		if err := srv.PushUpdate(newAlbumUpdate(id, "album")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const window = 200 * time.Millisecond
+	albums := make(chan []*telebot.Message, 1)
+	mw, _ := telebot.Albums(func(ctx context.Context, msgs []*telebot.Message) error {
+		// Buffered updates are not acknowledged before delivery
+		if offset, _ := store.Load(); offset != 0 {
+			t.Errorf("offset = %d before delivery, want 0", offset)
+		}
+		albums <- msgs
+		return nil
+	}, window)
+	h := mw(telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+		t.Errorf("unexpected update %d passed to next handler", upd.ID)
+		return nil
+	}))
+	start := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bot.Poll(ctx, &telebot.GetUpdates{Timeout: 1}, h)
+	msgs := <-albums
+	if d := time.Since(start); d < window {
+		t.Fatalf("album delivered after %v, want at least %v", d, window)
+	}
+	if len(msgs) != 3 || msgs[0].ID != 10 || msgs[1].ID != 11 || msgs[2].ID != 12 {
+		t.Fatalf("unexpected album %+v", msgs)
+	}
+	// Every buffered update is acknowledged after delivery
+	waitOffset(t, store, 4)
+}
+
+func TestAlbumsFlush(t *testing.T) {
+	var albums [][]*telebot.Message
+	mw, a := telebot.Albums(func(ctx context.Context, msgs []*telebot.Message) error {
+		albums = append(albums, msgs)
+		return nil
+	}, time.Hour)
+	var passed []int64
+	h := mw(telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+		passed = append(passed, upd.Message.ID)
+		return nil
+	}))
+	for _, upd := range []*telebot.Update{
+		newAlbumUpdate(2, "a"),
+		newAlbumUpdate(3, ""),
+		newAlbumUpdate(1, "a"),
+		newAlbumUpdate(4, "b"),
+	} {
+		if err := h.ServeUpdate(context.Background(), upd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Other messages are passed through immediately
+	if len(passed) != 1 || passed[0] != 3 {
+		t.Fatalf("passed messages %v, want [3]", passed)
+	}
+	if len(albums) != 0 {
+		t.Fatalf("got %d albums before flush, want 0", len(albums))
+	}
+	a.Flush()
+	if len(albums) != 2 {
+		t.Fatalf("got %d albums after flush, want 2", len(albums))
+	}
+	for _, msgs := range albums {
+		switch msgs[0].MediaGroupID {
+		case "a":
+			if len(msgs) != 2 || msgs[0].ID != 1 || msgs[1].ID != 2 {
+				t.Errorf("unexpected album a %+v", msgs)
+			}
+		case "b":
+			if len(msgs) != 1 || msgs[0].ID != 4 {
+				t.Errorf("unexpected album b %+v", msgs)
+			}
+		}
+	}
+}
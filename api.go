@@ -0,0 +1,57 @@
+package telebot
+
+import (
+	"context"
+	"io"
+)
+
+// API represents the Telegram Bot API methods implemented by Bot. Depend on
+// this interface instead of Bot in your application, so it can be replaced
+// with FakeBot in unit tests.
+type API interface {
+	GetUpdates(req *GetUpdates) ([]*Update, error)
+	GetUpdatesContext(ctx context.Context, req *GetUpdates) ([]*Update, error)
+	SetWebhook(req *SetWebhook) error
+	SetWebhookContext(ctx context.Context, req *SetWebhook) error
+	DeleteWebhook() (bool, error)
+	DeleteWebhookContext(ctx context.Context) (bool, error)
+	GetWebhookInfo() (*WebhookInfo, error)
+	GetWebhookInfoContext(ctx context.Context) (*WebhookInfo, error)
+	GetMe() (*User, error)
+	GetMeContext(ctx context.Context) (*User, error)
+	Send(req SendRequest) (*Message, error)
+	SendContext(ctx context.Context, req SendRequest) (*Message, error)
+	SendMediaGroup(req *SendMediaGroup) ([]*Message, error)
+	SendMediaGroupContext(ctx context.Context, req *SendMediaGroup) ([]*Message, error)
+	AnswerCallbackQuery(req *AnswerCallbackQuery) (bool, error)
+	AnswerCallbackQueryContext(ctx context.Context, req *AnswerCallbackQuery) (bool, error)
+	DeleteMessage(req *DeleteMessage) (bool, error)
+	DeleteMessageContext(ctx context.Context, req *DeleteMessage) (bool, error)
+	AnswerInlineQuery(req *AnswerInlineQuery) (bool, error)
+	AnswerInlineQueryContext(ctx context.Context, req *AnswerInlineQuery) (bool, error)
+	GetFile(req *GetFile) (*File, error)
+	GetFileContext(ctx context.Context, req *GetFile) (*File, error)
+	DownloadFile(ctx context.Context, fileID string) (io.ReadCloser, error)
+	GetChat(req *GetChat) (*Chat, error)
+	GetChatContext(ctx context.Context, req *GetChat) (*Chat, error)
+	GetChatAdministrators(req *GetChatAdministrators) ([]*ChatMember, error)
+	GetChatAdministratorsContext(ctx context.Context, req *GetChatAdministrators) ([]*ChatMember, error)
+	GetChatMember(req *GetChatMember) (*ChatMember, error)
+	GetChatMemberContext(ctx context.Context, req *GetChatMember) (*ChatMember, error)
+	GetChatMemberCount(req *GetChatMemberCount) (int, error)
+	GetChatMemberCountContext(ctx context.Context, req *GetChatMemberCount) (int, error)
+	BanChatMember(req *BanChatMember) (bool, error)
+	BanChatMemberContext(ctx context.Context, req *BanChatMember) (bool, error)
+	UnbanChatMember(req *UnbanChatMember) (bool, error)
+	UnbanChatMemberContext(ctx context.Context, req *UnbanChatMember) (bool, error)
+	RestrictChatMember(req *RestrictChatMember) (bool, error)
+	RestrictChatMemberContext(ctx context.Context, req *RestrictChatMember) (bool, error)
+	PromoteChatMember(req *PromoteChatMember) (bool, error)
+	PromoteChatMemberContext(ctx context.Context, req *PromoteChatMember) (bool, error)
+}
+
+// Make sure Bot and FakeBot implement the API interface.
+var (
+	_ API = (*Bot)(nil)
+	_ API = (*FakeBot)(nil)
+)
@@ -6,25 +6,42 @@ package telebot
 
 import (
 	"context"
-	"time"
+	"sync"
 )
 
 const (
 	// EndpointURL represents the Telegram Bot API endpoint URL.
 	EndpointURL = "https://api.telegram.org/bot"
 
-	// DefaultBackoffPeriod used to hold polling when an error occured.
-	DefaultBackoffPeriod = 5 * time.Second
-
 	// DefaultPollTimeout used to set the polling timeout in seconds.
 	DefaultPollTimeout = 60
 )
 
-// Bot implements the Telegram Bot API interface.
+// Bot implements the Telegram Bot API interface. Backoff is used to hold
+// polling when an error occured. ErrorHandler receives errors from polling,
+// webhook and update handlers, which are logged with the standard logger if
+// it is nil. Offsets persists the polling offset if set.
 type Bot struct {
-	Self          *User
-	BackoffPeriod time.Duration
-	caller        *Caller
+	Self         *User
+	Backoff      Backoff
+	ErrorHandler func(err error)
+	Offsets      OffsetStore
+	caller       *Caller
+	limiter      *RateLimiter
+	selfMu       sync.Mutex
+}
+
+// SetRetryPolicy enables retrying failed requests with policy p. Use nil to
+// disable retry, which is the default. It must be set before any call is made.
+func (b *Bot) SetRetryPolicy(p *RetryPolicy) {
+	b.caller.SetRetryPolicy(p)
+}
+
+// SetRateLimiter throttles send requests with rate limiter l. Use nil to
+// disable rate limiting, which is the default. It must be set before any call
+// is made.
+func (b *Bot) SetRateLimiter(l *RateLimiter) {
+	b.limiter = l
 }
 
 // GetUpdates sets parameter for GetUpdates and PollUpdates method.
@@ -38,68 +55,130 @@ type GetUpdates struct {
 // GetUpdates receive incoming updates using polling request. To continuously
 // poll updates, use the PollUpdates method instead.
 func (b *Bot) GetUpdates(req *GetUpdates) ([]*Update, error) {
+	return b.GetUpdatesContext(context.Background(), req)
+}
+
+// GetUpdatesContext is like GetUpdates but with cancellation context.
+func (b *Bot) GetUpdatesContext(ctx context.Context, req *GetUpdates) ([]*Update, error) {
 	var updates []*Update
-	err := b.caller.Poll("getUpdates", req, &updates)
+	err := b.caller.PollContext(ctx, "getUpdates", req, &updates)
 	return updates, err
 }
 
-// PollUpdates continously polls for updates with channel. Do not reuse request
-// after this call because it internally updates the offset number to fetch the
-// next updates. If timeout not set, it will automatically set to 60 seconds to
-// prevent short polling. To stop the polling, pass a cancellation context into
-// ctx. Otherwise, use nil or background context.
-func (b *Bot) PollUpdates(ctx context.Context, req *GetUpdates) (chan *Update, chan error) {
-	// Fill context with background if nil
-	if ctx == nil {
-		ctx = context.Background()
-	}
+// Poll continously polls for updates and calls h for each of them serially.
+// Do not reuse request after this call because it internally updates the
+// offset number to fetch the next updates. If timeout not set, it will
+// automatically set to 60 seconds to prevent short polling. It blocks until ctx
+// is cancelled, which also aborts the in-flight request, and returns the
+// context error.
+//
+// An update is acknowledged once the handler returns, or later when the
+// handler uses DeferAck. The offset only advances past acknowledged updates
+// and is committed to the Offsets store if set, so polling resumes from the
+// first unacknowledged update after restart.
+func (b *Bot) Poll(ctx context.Context, req *GetUpdates, h Handler) error {
 	// Increase timeout value if unset to prevent short polling
 	if req.Timeout <= 0 {
 		req.Timeout = DefaultPollTimeout
 	}
-	// Create channel for update and error
-	retupd, reterr := make(chan *Update), make(chan error)
-	// Create goroutine to enable asynchronous update mechanism
-	go func() {
-		// Get done channel
-		done := ctx.Done()
-		// Loop until cancellation
-		for {
-			select {
-			case <-done:
-				// Close update and error channel
-				close(retupd)
-				close(reterr)
-				// Exit from goroutine
-				return
-			default:
-				// Get updates
-				upds, err := b.GetUpdates(req)
-				// Check for error
-				if err != nil {
-					// Return error to channel and sleep for backoff period
-					reterr <- err
-					time.Sleep(b.BackoffPeriod)
-					continue
-				}
-				// Iterate over updates and return it to update channel
-				for _, upd := range upds {
-					retupd <- upd
-					// Change offset value and increment it by one
-					if req.Offset <= upd.ID {
-						req.Offset = upd.ID + 1
-					}
-				}
+	// Resume from the stored offset
+	if b.Offsets != nil {
+		offset, err := b.Offsets.Load()
+		if err != nil {
+			return err
+		}
+		if req.Offset < offset {
+			req.Offset = offset
+		}
+	}
+	tracker := newAckTracker(b, req.Offset)
+	next := req.Offset
+	// Loop until cancellation
+	for attempt := 0; ; {
+		// Get updates after the acknowledged offset
+		req.Offset = tracker.current()
+		upds, err := b.GetUpdatesContext(ctx, req)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Report error and hold polling for backoff period
+		if err != nil {
+			reportError(b.ErrorHandler, err)
+			if err = sleepContext(ctx, b.Backoff.Duration(attempt)); err != nil {
+				return err
 			}
+			attempt++
+			continue
 		}
+		attempt = 0
+		// Iterate over updates and pass it to handler
+		delivered := false
+		for _, upd := range upds {
+			// Skip in-flight update that is not acknowledged yet
+			if upd.ID < next {
+				continue
+			}
+			delivered = true
+			next = upd.ID + 1
+			ack := tracker.add(upd.ID)
+			err = h.ServeUpdate(context.WithValue(ctx, ackKey{}, ack), upd)
+			// Leave the update unacknowledged so it will be fetched again
+			if err != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				reportError(b.ErrorHandler, err)
+			}
+			if !ack.deferred {
+				ack.ack()
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+		// Wait for in-flight updates instead of fetching them repeatedly
+		if len(upds) > 0 && !delivered {
+			if err = tracker.wait(ctx, req.Offset); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// PollUpdates continously polls for updates with channel. The channel will be
+// closed once ctx is cancelled. Polling errors are passed to ErrorHandler, so
+// they never block the update delivery. An update is acknowledged once it is
+// received from the channel. See Poll for the request parameter usage.
+func (b *Bot) PollUpdates(ctx context.Context, req *GetUpdates) <-chan *Update {
+	// Fill context with background if nil
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Create goroutine to enable asynchronous update mechanism
+	retupd := make(chan *Update)
+	go func() {
+		defer close(retupd)
+		b.Poll(ctx, req, updateChannel(retupd))
 	}()
-	// Return channels
-	return retupd, reterr
+	return retupd
+}
+
+// updateChannel creates handler that passes update into ch.
+func updateChannel(ch chan<- *Update) Handler {
+	return HandlerFunc(func(ctx context.Context, upd *Update) error {
+		select {
+		case ch <- upd:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
 }
 
 // SetWebhook sets parameter for SetWebhook method.
 type SetWebhook struct {
 	URL            string       `json:"url"`
+	Certificate    *InputFile   `json:"certificate,omitempty"`
 	MaxConnections int          `json:"max_connections,omitempty"`
 	AllowedUpdates []UpdateType `json:"allowed_updates,omitempty"`
 }
@@ -110,28 +189,48 @@ type SetWebhook struct {
 // automated webhook setup with callback handler, use StartWebhook method
 // instead.
 func (b *Bot) SetWebhook(req *SetWebhook) error {
-	return b.caller.Call("setWebhook", req, nil)
+	return b.SetWebhookContext(context.Background(), req)
+}
+
+// SetWebhookContext is like SetWebhook but with cancellation context.
+func (b *Bot) SetWebhookContext(ctx context.Context, req *SetWebhook) error {
+	return b.caller.CallContext(ctx, "setWebhook", req, nil)
 }
 
 // DeleteWebhook removes webhook integration if you decide to switch back to
 // GetUpdates or PollUpdates method. Returns True on success.
 func (b *Bot) DeleteWebhook() (bool, error) {
+	return b.DeleteWebhookContext(context.Background())
+}
+
+// DeleteWebhookContext is like DeleteWebhook but with cancellation context.
+func (b *Bot) DeleteWebhookContext(ctx context.Context) (bool, error) {
 	var ok bool
-	err := b.caller.Call("deleteWebhook", nil, &ok)
+	err := b.caller.CallContext(ctx, "deleteWebhook", nil, &ok)
 	return ok, err
 }
 
 // GetWebhookInfo gets current webhook status.
 func (b *Bot) GetWebhookInfo() (*WebhookInfo, error) {
+	return b.GetWebhookInfoContext(context.Background())
+}
+
+// GetWebhookInfoContext is like GetWebhookInfo but with cancellation context.
+func (b *Bot) GetWebhookInfoContext(ctx context.Context) (*WebhookInfo, error) {
 	var info WebhookInfo
-	err := b.caller.Call("getWebhookInfo", nil, &info)
+	err := b.caller.CallContext(ctx, "getWebhookInfo", nil, &info)
 	return &info, err
 }
 
 // GetMe returns basic information about the bot in form of a User object.
 func (b *Bot) GetMe() (*User, error) {
+	return b.GetMeContext(context.Background())
+}
+
+// GetMeContext is like GetMe but with cancellation context.
+func (b *Bot) GetMeContext(ctx context.Context) (*User, error) {
 	var me User
-	err := b.caller.Call("getMe", nil, &me)
+	err := b.caller.CallContext(ctx, "getMe", nil, &me)
 	return &me, err
 }
 
@@ -172,6 +271,27 @@ const (
 	// EditMessageReplyMarkupRequest represents the edit message reply markup
 	// request type.
 	EditMessageReplyMarkupRequest SendRequestType = "editMessageReplyMarkup"
+
+	// SendPhotoRequest represents the send photo request type.
+	SendPhotoRequest SendRequestType = "sendPhoto"
+
+	// SendAudioRequest represents the send audio request type.
+	SendAudioRequest SendRequestType = "sendAudio"
+
+	// SendDocumentRequest represents the send document request type.
+	SendDocumentRequest SendRequestType = "sendDocument"
+
+	// SendVideoRequest represents the send video request type.
+	SendVideoRequest SendRequestType = "sendVideo"
+
+	// SendAnimationRequest represents the send animation request type.
+	SendAnimationRequest SendRequestType = "sendAnimation"
+
+	// SendVoiceRequest represents the send voice request type.
+	SendVoiceRequest SendRequestType = "sendVoice"
+
+	// SendVideoNoteRequest represents the send video note request type.
+	SendVideoNoteRequest SendRequestType = "sendVideoNote"
 )
 
 // SendRequest represents generic send request that can be distinguished by its
@@ -182,8 +302,19 @@ type SendRequest interface {
 
 // Send processes send request into a proper API call with type detection.
 func (b *Bot) Send(req SendRequest) (*Message, error) {
+	return b.SendContext(context.Background(), req)
+}
+
+// SendContext is like Send but with cancellation context. If rate limiter is
+// set, it waits for the chat and global limit before sending the request.
+func (b *Bot) SendContext(ctx context.Context, req SendRequest) (*Message, error) {
+	if b.limiter != nil {
+		if err := b.limiter.Wait(ctx, chatIDOf(req)); err != nil {
+			return nil, err
+		}
+	}
 	var msg Message
-	err := b.caller.Call(string(req.Type()), req, &msg)
+	err := b.caller.CallContext(ctx, string(req.Type()), req, &msg)
 	return &msg, err
 }
 
@@ -355,8 +486,14 @@ type AnswerCallbackQuery struct {
 // keyboards. The answer will be displayed to the user as a notification at the
 // top of the chat screen or as an alert.
 func (b *Bot) AnswerCallbackQuery(req *AnswerCallbackQuery) (bool, error) {
+	return b.AnswerCallbackQueryContext(context.Background(), req)
+}
+
+// AnswerCallbackQueryContext is like AnswerCallbackQuery but with cancellation
+// context.
+func (b *Bot) AnswerCallbackQueryContext(ctx context.Context, req *AnswerCallbackQuery) (bool, error) {
 	var ok bool
-	err := b.caller.Call("answerCallbackQuery", req, &ok)
+	err := b.caller.CallContext(ctx, "answerCallbackQuery", req, &ok)
 	return ok, err
 }
 
@@ -368,8 +505,13 @@ type DeleteMessage struct {
 
 // DeleteMessage delete a message.
 func (b *Bot) DeleteMessage(req *DeleteMessage) (bool, error) {
+	return b.DeleteMessageContext(context.Background(), req)
+}
+
+// DeleteMessageContext is like DeleteMessage but with cancellation context.
+func (b *Bot) DeleteMessageContext(ctx context.Context, req *DeleteMessage) (bool, error) {
 	var ok bool
-	err := b.caller.Call("deleteMessage", req, &ok)
+	err := b.caller.CallContext(ctx, "deleteMessage", req, &ok)
 	return ok, err
 }
 
@@ -386,17 +528,50 @@ type AnswerInlineQuery struct {
 
 // AnswerInlineQuery send answers to an inline query.
 func (b *Bot) AnswerInlineQuery(req *AnswerInlineQuery) (bool, error) {
+	return b.AnswerInlineQueryContext(context.Background(), req)
+}
+
+// AnswerInlineQueryContext is like AnswerInlineQuery but with cancellation
+// context.
+func (b *Bot) AnswerInlineQueryContext(ctx context.Context, req *AnswerInlineQuery) (bool, error) {
 	var ok bool
-	err := b.caller.Call("answerInlineQuery", req, &ok)
+	err := b.caller.CallContext(ctx, "answerInlineQuery", req, &ok)
 	return ok, err
 }
 
+// Me returns the bot information. Unlike GetMe, it only calls getMe once and
+// caches the result into Self field. Use this method when NewBot is called
+// with WithoutGetMe option.
+func (b *Bot) Me(ctx context.Context) (*User, error) {
+	b.selfMu.Lock()
+	defer b.selfMu.Unlock()
+	// Return cached bot information
+	if b.Self != nil {
+		return b.Self, nil
+	}
+	me, err := b.GetMeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.Self = me
+	return me, nil
+}
+
 // NewBot create new Bot from access token.
-func NewBot(token string) (*Bot, error) {
+func NewBot(token string, opts ...Option) (*Bot, error) {
+	o := newOptions(EndpointURL, opts)
 	// Create new bot instance
 	bot := &Bot{
-		BackoffPeriod: DefaultBackoffPeriod,
-		caller:        NewCaller(EndpointURL, token),
+		Backoff: Backoff{
+			Min:    DefaultMinBackoff,
+			Max:    DefaultMaxBackoff,
+			Jitter: DefaultBackoffJitter,
+		},
+		caller: newCaller(token, o),
+	}
+	// Skip connection test if bot information is looked up lazily
+	if o.skipGetMe {
+		return bot, nil
 	}
 	// Test connecton with getMe method
 	var err error
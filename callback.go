@@ -0,0 +1,162 @@
+package telebot
+
+import (
+	"context"
+	"strings"
+)
+
+// Callback represents a callback query matched by CallbackRouter with the
+// parameters extracted from its data.
+type Callback struct {
+	Query  *CallbackQuery
+	Params map[string]string
+}
+
+// Param gets the value of parameter name or empty string if it does not exist.
+func (c *Callback) Param(name string) string {
+	return c.Params[name]
+}
+
+// CallbackHandlerFunc handles a matched callback query.
+type CallbackHandlerFunc func(ctx context.Context, cb *Callback) error
+
+// callbackPattern represents a compiled callback data pattern. Literals and
+// parameters are interleaved, starting and ending with a literal which may be
+// empty.
+type callbackPattern struct {
+	literals []string
+	params   []string
+	prefix   bool
+}
+
+// compileCallbackPattern compiles pattern like "vote:{poll}:{choice}".
+func compileCallbackPattern(pattern string) *callbackPattern {
+	p := &callbackPattern{}
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		p.literals = append(p.literals, pattern[:start])
+		p.params = append(p.params, pattern[start+1:end])
+		pattern = pattern[end+1:]
+	}
+	p.literals = append(p.literals, pattern)
+	return p
+}
+
+// match matches data against the pattern and returns the parameters.
+func (p *callbackPattern) match(data string) (map[string]string, bool) {
+	if !strings.HasPrefix(data, p.literals[0]) {
+		return nil, false
+	}
+	data = data[len(p.literals[0]):]
+	params := make(map[string]string, len(p.params))
+	for i, name := range p.params {
+		lit := p.literals[i+1]
+		last := i == len(p.params)-1 && !p.prefix
+		// Find where the parameter value ends
+		n := -1
+		switch {
+		case last && strings.HasSuffix(data, lit):
+			n = len(data) - len(lit)
+		case !last && len(lit) > 0:
+			n = strings.Index(data, lit)
+		}
+		// Parameter value cannot be empty
+		if n <= 0 {
+			return nil, false
+		}
+		params[name] = data[:n]
+		data = data[n+len(lit):]
+	}
+	return params, p.prefix || len(data) == 0
+}
+
+// callbackRoute represents a handler registered into CallbackRouter.
+type callbackRoute struct {
+	pattern *callbackPattern
+	fn      CallbackHandlerFunc
+}
+
+// CallbackRouter dispatches callback queries to the handler registered for
+// their data. Routes are matched in registration order and the first matching
+// route wins. Register all routes before serving updates.
+type CallbackRouter struct {
+	routes   []*callbackRoute
+	fallback CallbackQueryHandlerFunc
+	api      API
+}
+
+// NewCallbackRouter creates new empty callback router.
+func NewCallbackRouter() *CallbackRouter {
+	return &CallbackRouter{}
+}
+
+// Handle registers fn for callback data matching pattern exactly. Pattern may
+// contain named parameters enclosed in braces, such as "page:{n}" or
+// "vote:{poll}:{choice}". A parameter matches at least one character up to the
+// next literal, while the last parameter matches the rest of data.
+func (r *CallbackRouter) Handle(pattern string, fn CallbackHandlerFunc) {
+	r.routes = append(r.routes, &callbackRoute{
+		pattern: compileCallbackPattern(pattern),
+		fn:      fn,
+	})
+}
+
+// HandlePrefix registers fn for callback data starting with pattern. Pattern
+// may contain parameters like in Handle, but the last parameter must be
+// followed by a literal, otherwise it panics.
+func (r *CallbackRouter) HandlePrefix(pattern string, fn CallbackHandlerFunc) {
+	p := compileCallbackPattern(pattern)
+	if len(p.params) > 0 && len(p.literals[len(p.literals)-1]) == 0 {
+		panic("telebot: callback prefix pattern " + pattern + " ends with a parameter")
+	}
+	p.prefix = true
+	r.routes = append(r.routes, &callbackRoute{pattern: p, fn: fn})
+}
+
+// HandleFallback registers fn for callback queries without matching route.
+func (r *CallbackRouter) HandleFallback(fn CallbackQueryHandlerFunc) {
+	r.fallback = fn
+}
+
+// AnswerUnmatched makes the router answer callback queries without matching
+// route and fallback handler with api, so the client stops showing the
+// progress indicator.
+func (r *CallbackRouter) AnswerUnmatched(api API) {
+	r.api = api
+}
+
+// ServeCallbackQuery dispatches query to the matching handler. It can be
+// registered with Router.HandleCallbackQuery.
+func (r *CallbackRouter) ServeCallbackQuery(ctx context.Context, query *CallbackQuery) error {
+	for _, rt := range r.routes {
+		if params, ok := rt.pattern.match(query.Data); ok {
+			return rt.fn(ctx, &Callback{Query: query, Params: params})
+		}
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, query)
+	}
+	if r.api != nil {
+		_, err := r.api.AnswerCallbackQueryContext(ctx, &AnswerCallbackQuery{
+			CallbackQueryID: query.ID,
+		})
+		return err
+	}
+	return nil
+}
+
+// ServeUpdate implements the Handler interface.
+func (r *CallbackRouter) ServeUpdate(ctx context.Context, upd *Update) error {
+	if upd.CallbackQuery == nil {
+		return nil
+	}
+	return r.ServeCallbackQuery(ctx, upd.CallbackQuery)
+}
@@ -0,0 +1,53 @@
+package telebot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCallbackPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		prefix  bool
+		data    string
+		params  map[string]string
+		ok      bool
+	}{
+		{"exact", false, "exact", map[string]string{}, true},
+		{"exact", false, "exactly", nil, false},
+		{"page:{n}", false, "page:3", map[string]string{"n": "3"}, true},
+		{"page:{n}", false, "page:", nil, false},
+		{"page:{n}", false, "page:3:4", map[string]string{"n": "3:4"}, true},
+		{"vote:{poll}:{choice}", false, "vote:p1:yes", map[string]string{"poll": "p1", "choice": "yes"}, true},
+		{"vote:{poll}:{choice}", false, "vote:p1:yes:no", map[string]string{"poll": "p1", "choice": "yes:no"}, true},
+		{"vote:{poll}:{choice}", false, "vote::yes", nil, false},
+		{"vote:{poll}:{choice}", false, "page:3", nil, false},
+		{"x{a}y", false, "x1yy", map[string]string{"a": "1y"}, true},
+		{"x{a}y", false, "x1", nil, false},
+		{"menu:", true, "menu:a:b", map[string]string{}, true},
+		{"menu:", true, "men", nil, false},
+		{"menu:{id}:", true, "menu:7:open", map[string]string{"id": "7"}, true},
+		{"menu:{id}:", true, "menu:7", nil, false},
+	}
+	for _, tt := range tests {
+		p := compileCallbackPattern(tt.pattern)
+		p.prefix = tt.prefix
+		params, ok := p.match(tt.data)
+		if ok != tt.ok {
+			t.Errorf("%q.match(%q) ok = %v, want %v", tt.pattern, tt.data, ok, tt.ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("%q.match(%q) params = %v, want %v", tt.pattern, tt.data, params, tt.params)
+		}
+	}
+}
+
+func TestCallbackRouterHandlePrefixPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("HandlePrefix did not panic on trailing parameter")
+		}
+	}()
+	NewCallbackRouter().HandlePrefix("menu:{id}", nil)
+}
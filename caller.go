@@ -2,16 +2,24 @@ package telebot
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"mime/multipart"
 	"net/http"
-	"time"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 // Error represents error from Telegram Bot API.
 type Error struct {
-	HTTPCode    int    `json:"http_code"`
-	Description string `json:"description"`
+	HTTPCode        int    `json:"http_code"`
+	ErrorCode       int    `json:"error_code"`
+	Description     string `json:"description"`
+	RetryAfter      int    `json:"retry_after,omitempty"`
+	MigrateToChatID int64  `json:"migrate_to_chat_id,omitempty"`
 }
 
 // Error implements the error interface.
@@ -19,49 +27,136 @@ func (e *Error) Error() string {
 	return e.Description
 }
 
+// code returns Telegram error code or fallback to HTTP status code.
+func (e *Error) code() int {
+	if e.ErrorCode != 0 {
+		return e.ErrorCode
+	}
+	return e.HTTPCode
+}
+
+// asError extracts API error from err.
+func asError(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// IsTooManyRequests reports whether err is caused by flood control. Use the
+// RetryAfter field of Error to find out how long to wait before retrying.
+func IsTooManyRequests(err error) bool {
+	e, ok := asError(err)
+	return ok && e.code() == http.StatusTooManyRequests
+}
+
+// IsForbidden reports whether err is caused by the bot has no rights to
+// perform the request, such as being kicked from the chat.
+func IsForbidden(err error) bool {
+	e, ok := asError(err)
+	return ok && e.code() == http.StatusForbidden
+}
+
+// IsBotBlocked reports whether err is caused by the user blocking the bot.
+func IsBotBlocked(err error) bool {
+	e, ok := asError(err)
+	return ok && e.code() == http.StatusForbidden &&
+		strings.Contains(e.Description, "bot was blocked by the user")
+}
+
+// IsMessageNotModified reports whether err is caused by editing a message
+// with exactly the same content and reply markup.
+func IsMessageNotModified(err error) bool {
+	e, ok := asError(err)
+	return ok && e.code() == http.StatusBadRequest &&
+		strings.Contains(e.Description, "message is not modified")
+}
+
+// IsChatNotFound reports whether err is caused by unknown or inaccessible
+// chat.
+func IsChatNotFound(err error) bool {
+	e, ok := asError(err)
+	return ok && e.code() == http.StatusBadRequest &&
+		strings.Contains(e.Description, "chat not found")
+}
+
+// IsChatMigrated reports whether err is caused by the group being migrated to
+// a supergroup. Use the MigrateToChatID field of Error to get the new chat
+// identifier.
+func IsChatMigrated(err error) bool {
+	e, ok := asError(err)
+	return ok && e.MigrateToChatID != 0
+}
+
 // Caller is a low-level interface to wrap REST API request/response with http
 // client into an RPC method. You should not directly use Caller from your
 // application.
 type Caller struct {
 	prefix     string
+	filePrefix string
+	localMode  bool
 	client     *http.Client
 	pollClient *http.Client
+	retry      *RetryPolicy
 }
 
 // outerResponse sets a standard message formatting for result data on request
 // success or error message on request fail.
 type outerResponse struct {
-	Ok          bool            `json:"ok"`
-	Result      json.RawMessage `json:"result"`
-	Description string          `json:"description"`
+	Ok          bool                `json:"ok"`
+	Result      json.RawMessage     `json:"result"`
+	ErrorCode   int                 `json:"error_code"`
+	Description string              `json:"description"`
+	Parameters  *ResponseParameters `json:"parameters"`
+}
+
+func (c *Caller) do(ctx context.Context, client *http.Client, name string, request, response interface{}) error {
+	// Call directly if retry is disabled or request cannot be replayed
+	if c.retry == nil || len(uploadFiles(request)) > 0 {
+		return c.roundTrip(ctx, client, name, request, response)
+	}
+	return c.retry.do(ctx, name, func() error {
+		return c.roundTrip(ctx, client, name, request, response)
+	})
 }
 
-func (c *Caller) do(client *http.Client, name string, request, response interface{}) error {
-	// Store HTTP method and optional input reader
-	var method string
+func (c *Caller) roundTrip(ctx context.Context, client *http.Client, name string, request, response interface{}) error {
+	// Store HTTP method, content type and optional input reader
+	var method, contentType string
 	var input io.Reader
 	// Check if method call has request body
 	if request != nil {
 		// Set HTTP method to POST
 		method = "POST"
-		// Marshal request into JSON and set body reader
-		buf, err := json.Marshal(request)
-		if err != nil {
-			return err
+		// Check if request has files to be uploaded
+		if files := uploadFiles(request); len(files) > 0 {
+			// Stream request as multipart form with pipe
+			pr, pw := io.Pipe()
+			defer pr.Close()
+			mw := multipart.NewWriter(pw)
+			go func() {
+				pw.CloseWithError(writeMultipart(mw, request, files))
+			}()
+			input, contentType = pr, mw.FormDataContentType()
+		} else {
+			// Marshal request into JSON and set body reader
+			buf, err := json.Marshal(request)
+			if err != nil {
+				return err
+			}
+			input, contentType = bytes.NewBuffer(buf), "application/json"
 		}
-		input = bytes.NewBuffer(buf)
 	} else {
 		// Set HTTP method to GET
 		method = "GET"
 	}
 	// Create HTTP request data
-	req, err := http.NewRequest(method, c.prefix+name, input)
+	req, err := http.NewRequestWithContext(ctx, method, c.prefix+name, input)
 	if err != nil {
 		return err
 	}
 	// Add content-type to header
 	if request != nil {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
 	}
 	// Do HTTP transaction
 	res, err := client.Do(req)
@@ -72,21 +167,32 @@ func (c *Caller) do(client *http.Client, name string, request, response interfac
 	var outer outerResponse
 	if err = json.NewDecoder(res.Body).Decode(&outer); err != nil {
 		res.Body.Close()
+		// Keep HTTP status code of non-API error response, such as proxy
+		// failure
+		if res.StatusCode >= 400 {
+			return &Error{
+				HTTPCode:    res.StatusCode,
+				Description: http.StatusText(res.StatusCode),
+			}
+		}
 		return err
 	}
 	res.Body.Close()
 	// Check if telegram API return an error instead
 	if !outer.Ok {
-		if len(outer.Description) == 0 {
-			return &Error{
-				HTTPCode:    res.StatusCode,
-				Description: "undefined error",
-			}
-		}
-		return &Error{
+		apierr := &Error{
 			HTTPCode:    res.StatusCode,
+			ErrorCode:   outer.ErrorCode,
 			Description: outer.Description,
 		}
+		if len(apierr.Description) == 0 {
+			apierr.Description = "undefined error"
+		}
+		if outer.Parameters != nil {
+			apierr.RetryAfter = outer.Parameters.RetryAfter
+			apierr.MigrateToChatID = outer.Parameters.MigrateToChatID
+		}
+		return apierr
 	}
 	// Unmarshal inner response if asked
 	if response != nil {
@@ -103,7 +209,13 @@ func (c *Caller) do(client *http.Client, name string, request, response interfac
 // decoded result from JSON response. If method takes no request parameter
 // and/or response data, leave them with nil value.
 func (c *Caller) Call(name string, request, response interface{}) error {
-	return c.do(c.client, name, request, response)
+	return c.CallContext(context.Background(), name, request, response)
+}
+
+// CallContext is like Call but with cancellation context. The request will be
+// aborted once ctx is cancelled or its deadline exceeded.
+func (c *Caller) CallContext(ctx context.Context, name string, request, response interface{}) error {
+	return c.do(ctx, c.client, name, request, response)
 }
 
 // Poll a method name to Telegram API. This method yields a long timeout value
@@ -112,19 +224,63 @@ func (c *Caller) Call(name string, request, response interface{}) error {
 // that can receive decoded result from JSON response. If method takes no
 // request parameter and/or response data, leave them with nil value.
 func (c *Caller) Poll(name string, request, response interface{}) error {
-	return c.do(c.pollClient, name, request, response)
+	return c.PollContext(context.Background(), name, request, response)
+}
+
+// PollContext is like Poll but with cancellation context. The request will be
+// aborted once ctx is cancelled or its deadline exceeded.
+func (c *Caller) PollContext(ctx context.Context, name string, request, response interface{}) error {
+	return c.do(ctx, c.pollClient, name, request, response)
+}
+
+// Download opens the file at path returned by getFile from the file endpoint.
+// In local mode, absolute path is opened from the local filesystem instead.
+// The client timeout does not apply to the download, so use ctx to abort it.
+// Close the returned reader when done.
+func (c *Caller) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	if c.localMode && filepath.IsAbs(path) {
+		return os.Open(path)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.filePrefix+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Copy client without timeout to allow large files
+	client := *c.client
+	client.Timeout = 0
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, &Error{
+			HTTPCode:    res.StatusCode,
+			Description: http.StatusText(res.StatusCode),
+		}
+	}
+	return res.Body, nil
+}
+
+// SetRetryPolicy enables retrying failed requests with policy p. Use nil to
+// disable retry, which is the default. It must be set before any call is made.
+func (c *Caller) SetRetryPolicy(p *RetryPolicy) {
+	c.retry = p
 }
 
 // NewCaller creates new caller wraper given telegram bot API endpoint and
 // token. You should not directly call NewCaller from your application.
-func NewCaller(endpoint, token string) *Caller {
+func NewCaller(endpoint, token string, opts ...Option) *Caller {
+	return newCaller(token, newOptions(endpoint, opts))
+}
+
+// newCaller creates new caller from parsed options.
+func newCaller(token string, o *options) *Caller {
 	return &Caller{
-		prefix: endpoint + token + "/",
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		pollClient: &http.Client{
-			Timeout: 5 * time.Minute,
-		},
+		prefix:     o.endpoint + token + "/",
+		filePrefix: o.fileEndpoint + token + "/",
+		localMode:  o.localMode,
+		client:     o.client,
+		pollClient: o.pollClient,
 	}
 }
@@ -0,0 +1,88 @@
+package telebot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adzil/telebot"
+	"github.com/adzil/telebot/telebottest"
+)
+
+func newRetryBot(t *testing.T, srv *telebottest.Server) *telebot.Bot {
+	t.Helper()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot.SetRetryPolicy(&telebot.RetryPolicy{
+		MaxRetries: 2,
+		Backoff:    telebot.Backoff{Min: time.Millisecond},
+	})
+	return bot
+}
+
+func TestCallerRetryAfter(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	srv.AddChat(&telebot.Chat{ID: 1, Type: telebot.PrivateChat})
+	bot := newRetryBot(t, srv)
+	srv.Fail("sendMessage", &telebot.Error{
+		ErrorCode:   429,
+		Description: "Too Many Requests: retry after 1",
+		RetryAfter:  1,
+	})
+	start := time.Now()
+	if _, err := bot.Send(&telebot.SendMessage{ChatID: 1, Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Fatalf("retried after %v, want at least 1s", d)
+	}
+	if n := len(srv.CallsTo("sendMessage")); n != 2 {
+		t.Fatalf("got %d sendMessage calls, want 2", n)
+	}
+}
+
+func TestCallerRetryLimit(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot := newRetryBot(t, srv)
+	for i := 0; i < 3; i++ {
+		srv.Fail("getChat", &telebot.Error{ErrorCode: 502, Description: "Bad Gateway"})
+	}
+	_, err := bot.GetChat(&telebot.GetChat{ChatID: 1})
+	if apierr, ok := err.(*telebot.Error); !ok || apierr.HTTPCode != 502 {
+		t.Fatalf("GetChat returned %v, want Bad Gateway", err)
+	}
+	if n := len(srv.CallsTo("getChat")); n != 3 {
+		t.Fatalf("got %d getChat calls, want 3", n)
+	}
+}
+
+func TestCallerNoRetry(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	srv.AddChat(&telebot.Chat{ID: 1, Type: telebot.PrivateChat})
+	bot := newRetryBot(t, srv)
+	// Client error is never retried
+	srv.Fail("sendMessage", &telebot.Error{ErrorCode: 400, Description: "Bad Request"})
+	if _, err := bot.Send(&telebot.SendMessage{ChatID: 1, Text: "hello"}); err == nil {
+		t.Fatal("Send succeeded, want error")
+	}
+	if n := len(srv.CallsTo("sendMessage")); n != 1 {
+		t.Fatalf("got %d sendMessage calls, want 1", n)
+	}
+	// Upload cannot be replayed even after flood control error
+	srv.Fail("sendPhoto", &telebot.Error{ErrorCode: 429, Description: "Too Many Requests", RetryAfter: 1})
+	_, err := bot.Send(&telebot.SendPhoto{
+		ChatID: 1,
+		Photo:  telebot.NewInputFileReader("photo.jpg", strings.NewReader("JPEG")),
+	})
+	if apierr, ok := err.(*telebot.Error); !ok || apierr.RetryAfter != 1 {
+		t.Fatalf("Send returned %v, want flood control error", err)
+	}
+	if n := len(srv.CallsTo("sendPhoto")); n != 1 {
+		t.Fatalf("got %d sendPhoto calls, want 1", n)
+	}
+}
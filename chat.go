@@ -0,0 +1,176 @@
+package telebot
+
+import "context"
+
+// GetChat sets parameter for GetChat method.
+type GetChat struct {
+	ChatID int64 `json:"chat_id"`
+}
+
+// GetChat get up to date information about the chat.
+func (b *Bot) GetChat(req *GetChat) (*Chat, error) {
+	return b.GetChatContext(context.Background(), req)
+}
+
+// GetChatContext is like GetChat but with cancellation context.
+func (b *Bot) GetChatContext(ctx context.Context, req *GetChat) (*Chat, error) {
+	var chat Chat
+	err := b.caller.CallContext(ctx, "getChat", req, &chat)
+	return &chat, err
+}
+
+// GetChatAdministrators sets parameter for GetChatAdministrators method.
+type GetChatAdministrators struct {
+	ChatID int64 `json:"chat_id"`
+}
+
+// GetChatAdministrators get a list of administrators in a chat except other
+// bots.
+func (b *Bot) GetChatAdministrators(req *GetChatAdministrators) ([]*ChatMember, error) {
+	return b.GetChatAdministratorsContext(context.Background(), req)
+}
+
+// GetChatAdministratorsContext is like GetChatAdministrators but with
+// cancellation context.
+func (b *Bot) GetChatAdministratorsContext(ctx context.Context, req *GetChatAdministrators) ([]*ChatMember, error) {
+	var members []*ChatMember
+	err := b.caller.CallContext(ctx, "getChatAdministrators", req, &members)
+	return members, err
+}
+
+// GetChatMember sets parameter for GetChatMember method.
+type GetChatMember struct {
+	ChatID int64 `json:"chat_id"`
+	UserID int64 `json:"user_id"`
+}
+
+// GetChatMember get information about a member of a chat.
+func (b *Bot) GetChatMember(req *GetChatMember) (*ChatMember, error) {
+	return b.GetChatMemberContext(context.Background(), req)
+}
+
+// GetChatMemberContext is like GetChatMember but with cancellation context.
+func (b *Bot) GetChatMemberContext(ctx context.Context, req *GetChatMember) (*ChatMember, error) {
+	var member ChatMember
+	err := b.caller.CallContext(ctx, "getChatMember", req, &member)
+	return &member, err
+}
+
+// GetChatMemberCount sets parameter for GetChatMemberCount method.
+type GetChatMemberCount struct {
+	ChatID int64 `json:"chat_id"`
+}
+
+// GetChatMemberCount get the number of members in a chat.
+func (b *Bot) GetChatMemberCount(req *GetChatMemberCount) (int, error) {
+	return b.GetChatMemberCountContext(context.Background(), req)
+}
+
+// GetChatMemberCountContext is like GetChatMemberCount but with cancellation
+// context.
+func (b *Bot) GetChatMemberCountContext(ctx context.Context, req *GetChatMemberCount) (int, error) {
+	var count int
+	err := b.caller.CallContext(ctx, "getChatMemberCount", req, &count)
+	return count, err
+}
+
+// BanChatMember sets parameter for BanChatMember method. User banned for more
+// than 366 days or less than 30 seconds from the current time are considered
+// to be banned forever.
+type BanChatMember struct {
+	ChatID         int64 `json:"chat_id"`
+	UserID         int64 `json:"user_id"`
+	UntilDate      int64 `json:"until_date,omitempty"`
+	RevokeMessages bool  `json:"revoke_messages,omitempty"`
+}
+
+// BanChatMember ban a user in a group, supergroup or channel. The user will
+// not be able to return to the chat on their own using invite links, unless
+// unbanned first. The bot must be an administrator in the chat with the
+// appropriate rights.
+func (b *Bot) BanChatMember(req *BanChatMember) (bool, error) {
+	return b.BanChatMemberContext(context.Background(), req)
+}
+
+// BanChatMemberContext is like BanChatMember but with cancellation context.
+func (b *Bot) BanChatMemberContext(ctx context.Context, req *BanChatMember) (bool, error) {
+	var ok bool
+	err := b.caller.CallContext(ctx, "banChatMember", req, &ok)
+	return ok, err
+}
+
+// UnbanChatMember sets parameter for UnbanChatMember method. If OnlyIfBanned
+// is not set, a current member of the chat will be removed from it.
+type UnbanChatMember struct {
+	ChatID       int64 `json:"chat_id"`
+	UserID       int64 `json:"user_id"`
+	OnlyIfBanned bool  `json:"only_if_banned,omitempty"`
+}
+
+// UnbanChatMember unban a previously banned user in a supergroup or channel.
+// The user will not return to the chat automatically, but will be able to join
+// via link.
+func (b *Bot) UnbanChatMember(req *UnbanChatMember) (bool, error) {
+	return b.UnbanChatMemberContext(context.Background(), req)
+}
+
+// UnbanChatMemberContext is like UnbanChatMember but with cancellation
+// context.
+func (b *Bot) UnbanChatMemberContext(ctx context.Context, req *UnbanChatMember) (bool, error) {
+	var ok bool
+	err := b.caller.CallContext(ctx, "unbanChatMember", req, &ok)
+	return ok, err
+}
+
+// RestrictChatMember sets parameter for RestrictChatMember method. Permissions
+// not set are restricted, so pass all the permissions to lift the
+// restrictions.
+type RestrictChatMember struct {
+	ChatID      int64           `json:"chat_id"`
+	UserID      int64           `json:"user_id"`
+	Permissions ChatPermissions `json:"permissions"`
+	UntilDate   int64           `json:"until_date,omitempty"`
+}
+
+// RestrictChatMember restrict a user in a supergroup. The bot must be an
+// administrator in the supergroup with the appropriate rights.
+func (b *Bot) RestrictChatMember(req *RestrictChatMember) (bool, error) {
+	return b.RestrictChatMemberContext(context.Background(), req)
+}
+
+// RestrictChatMemberContext is like RestrictChatMember but with cancellation
+// context.
+func (b *Bot) RestrictChatMemberContext(ctx context.Context, req *RestrictChatMember) (bool, error) {
+	var ok bool
+	err := b.caller.CallContext(ctx, "restrictChatMember", req, &ok)
+	return ok, err
+}
+
+// PromoteChatMember sets parameter for PromoteChatMember method. Leave all the
+// rights unset to demote a user.
+type PromoteChatMember struct {
+	ChatID             int64 `json:"chat_id"`
+	UserID             int64 `json:"user_id"`
+	CanChangeInfo      bool  `json:"can_change_info,omitempty"`
+	CanPostMessages    bool  `json:"can_post_messages,omitempty"`
+	CanEditMessages    bool  `json:"can_edit_messages,omitempty"`
+	CanDeleteMessages  bool  `json:"can_delete_messages,omitempty"`
+	CanInviteUsers     bool  `json:"can_invite_users,omitempty"`
+	CanRestrictMembers bool  `json:"can_restrict_members,omitempty"`
+	CanPinMessages     bool  `json:"can_pin_messages,omitempty"`
+	CanPromoteMembers  bool  `json:"can_promote_members,omitempty"`
+}
+
+// PromoteChatMember promote or demote a user in a supergroup or a channel. The
+// bot must be an administrator in the chat with the appropriate rights.
+func (b *Bot) PromoteChatMember(req *PromoteChatMember) (bool, error) {
+	return b.PromoteChatMemberContext(context.Background(), req)
+}
+
+// PromoteChatMemberContext is like PromoteChatMember but with cancellation
+// context.
+func (b *Bot) PromoteChatMemberContext(ctx context.Context, req *PromoteChatMember) (bool, error) {
+	var ok bool
+	err := b.caller.CallContext(ctx, "promoteChatMember", req, &ok)
+	return ok, err
+}
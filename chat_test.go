@@ -0,0 +1,55 @@
+package telebot_test
+
+import (
+	"testing"
+
+	"github.com/adzil/telebot"
+	"github.com/adzil/telebot/telebottest"
+)
+
+func TestRestrictChatMemberPermissions(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	srv.Handle("restrictChatMember", func(call *telebottest.Call) (interface{}, error) {
+		return true, nil
+	})
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		perms telebot.ChatPermissions
+		want  string
+	}{
+		{telebot.ChatPermissions{}, `{}`},
+		{telebot.ChatPermissions{CanSendMessages: true}, `{"can_send_messages":true}`},
+	}
+	for i, tt := range tests {
+		if _, err := bot.RestrictChatMember(&telebot.RestrictChatMember{ChatID: -1, UserID: 1, Permissions: tt.perms}); err != nil {
+			t.Fatal(err)
+		}
+		// Permissions are always sent, restricting everything when empty
+		if got := srv.CallsTo("restrictChatMember")[i].Params["permissions"]; got != tt.want {
+			t.Errorf("permissions = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestFakeBotRestrictChatMember(t *testing.T) {
+	f := telebot.NewFakeBot()
+	req := &telebot.RestrictChatMember{
+		ChatID:      -1,
+		UserID:      1,
+		Permissions: telebot.ChatPermissions{CanSendMessages: true},
+	}
+	if _, err := f.RestrictChatMember(req); err != nil {
+		t.Fatal(err)
+	}
+	member, err := f.GetChatMember(&telebot.GetChatMember{ChatID: -1, UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.Status != telebot.RestrictedMember || !member.CanSendMessages || member.CanSendMediaMessages {
+		t.Fatalf("unexpected member %+v", member)
+	}
+}
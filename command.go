@@ -0,0 +1,161 @@
+package telebot
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Command represents a bot command parsed from a message, such as
+// "/start@examplebot foo bar". Name is the lowercased command without the
+// leading slash and Username is the addressed bot username if any.
+type Command struct {
+	Name     string
+	Username string
+	Args     []string
+	RawArgs  string
+	Message  *Message
+}
+
+// ParseCommand extracts the bot command at the beginning of msg text using its
+// bot_command entity. It returns false if msg does not start with a command.
+func ParseCommand(msg *Message) (*Command, bool) {
+	if msg == nil {
+		return nil, false
+	}
+	// Find bot command entity at the beginning of the message
+	for _, ent := range msg.Entities {
+		if ent.Type != BotCommandEntity || ent.Offset != 0 {
+			continue
+		}
+		// Entity offset and length are measured in UTF-16 code units
+		text := utf16.Encode([]rune(msg.Text))
+		if ent.Length <= 1 || ent.Length > len(text) {
+			return nil, false
+		}
+		name := string(utf16.Decode(text[1:ent.Length]))
+		args := strings.TrimSpace(string(utf16.Decode(text[ent.Length:])))
+		cmd := &Command{
+			RawArgs: args,
+			Args:    SplitArgs(args),
+			Message: msg,
+		}
+		// Separate bot username from command name
+		if n := strings.IndexByte(name, '@'); n >= 0 {
+			name, cmd.Username = name[:n], name[n+1:]
+		}
+		cmd.Name = strings.ToLower(name)
+		return cmd, true
+	}
+	return nil, false
+}
+
+// SplitArgs splits s into arguments separated by white spaces. Arguments
+// enclosed in single or double quotes are kept as one argument and backslash
+// escapes the next character.
+func SplitArgs(s string) []string {
+	var args []string
+	var arg strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			inArg, escaped = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			inArg, quote = true, r
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			inArg = true
+			arg.WriteRune(r)
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// CommandHandlerFunc handles a parsed bot command.
+type CommandHandlerFunc func(ctx context.Context, cmd *Command) error
+
+// CommandRouter dispatches messages to the handler registered for their bot
+// command. Commands explicitly addressed to other bots, which may happen in
+// groups, are ignored. Register all commands before serving updates.
+type CommandRouter struct {
+	username string
+	commands map[string]CommandHandlerFunc
+	unknown  CommandHandlerFunc
+	fallback MessageHandlerFunc
+}
+
+// NewCommandRouter creates new command router for bot with username, such as
+// the one from Bot.Self or Bot.Me.
+func NewCommandRouter(username string) *CommandRouter {
+	return &CommandRouter{
+		username: username,
+		commands: make(map[string]CommandHandlerFunc),
+	}
+}
+
+// Handle registers fn for command name without the leading slash.
+func (r *CommandRouter) Handle(name string, fn CommandHandlerFunc) {
+	r.commands[strings.ToLower(strings.TrimPrefix(name, "/"))] = fn
+}
+
+// HandleUnknown registers fn for commands without registered handler.
+func (r *CommandRouter) HandleUnknown(fn CommandHandlerFunc) {
+	r.unknown = fn
+}
+
+// HandleFallback registers fn for messages that are not commands for this bot.
+func (r *CommandRouter) HandleFallback(fn MessageHandlerFunc) {
+	r.fallback = fn
+}
+
+// ServeMessage dispatches msg to the command handler. It can be registered
+// with Router.HandleMessage.
+func (r *CommandRouter) ServeMessage(ctx context.Context, msg *Message) error {
+	cmd, ok := ParseCommand(msg)
+	// Treat commands for other bots as regular messages
+	if ok && len(cmd.Username) > 0 && !strings.EqualFold(cmd.Username, r.username) {
+		ok = false
+	}
+	if !ok {
+		if r.fallback != nil {
+			return r.fallback(ctx, msg)
+		}
+		return nil
+	}
+	if fn, ok := r.commands[cmd.Name]; ok {
+		return fn(ctx, cmd)
+	}
+	if r.unknown != nil {
+		return r.unknown(ctx, cmd)
+	}
+	return nil
+}
+
+// ServeUpdate implements the Handler interface. Only new messages are
+// dispatched.
+func (r *CommandRouter) ServeUpdate(ctx context.Context, upd *Update) error {
+	if upd.Message == nil {
+		return nil
+	}
+	return r.ServeMessage(ctx, upd.Message)
+}
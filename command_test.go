@@ -0,0 +1,33 @@
+package telebot_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adzil/telebot"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		s    string
+		args []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"a b  c", []string{"a", "b", "c"}},
+		{" \ta\nb ", []string{"a", "b"}},
+		{`"b c" 'd e'`, []string{"b c", "d e"}},
+		{`"it's" 'say "hi"'`, []string{"it's", `say "hi"`}},
+		{`f\ g h\\i`, []string{"f g", `h\i`}},
+		{`"h\"i"`, []string{`h"i`}},
+		{`x"" ""`, []string{"x", ""}},
+		{`pre"quoted"post`, []string{"prequotedpost"}},
+		{`"unterminated quote`, []string{"unterminated quote"}},
+		{"héllo wörld", []string{"héllo", "wörld"}},
+	}
+	for _, tt := range tests {
+		if args := telebot.SplitArgs(tt.s); !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("SplitArgs(%q) = %q, want %q", tt.s, args, tt.args)
+		}
+	}
+}
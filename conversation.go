@@ -0,0 +1,284 @@
+package telebot
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultConversationTimeout used to expire idle conversation.
+	DefaultConversationTimeout = 10 * time.Minute
+
+	// DefaultCancelCommand used to abort active conversation.
+	DefaultCancelCommand = "cancel"
+)
+
+// State represents the state name of a conversation.
+type State string
+
+// Dialog holds the state and data bag of an active conversation between the
+// bot and a user in a chat. Messages of the same dialog are handled one at a
+// time, so a state handler can use the dialog without locking.
+type Dialog struct {
+	ChatID    int64
+	UserID    int64
+	State     State
+	Data      map[string]interface{}
+	UpdatedAt time.Time
+	next      State
+	ended     bool
+}
+
+// Get gets data value by key.
+func (d *Dialog) Get(key string) interface{} {
+	return d.Data[key]
+}
+
+// Set sets data value by key.
+func (d *Dialog) Set(key string, value interface{}) {
+	d.Data[key] = value
+}
+
+// Next moves the conversation into state after the handler returns.
+func (d *Dialog) Next(state State) {
+	d.next = state
+}
+
+// End finishes the conversation after the handler returns.
+func (d *Dialog) End() {
+	d.ended = true
+}
+
+// StateHandlerFunc handles a message of an active conversation.
+type StateHandlerFunc func(ctx context.Context, d *Dialog, msg *Message) error
+
+// dialogKey identifies a conversation.
+type dialogKey struct {
+	chatID int64
+	userID int64
+}
+
+// dialogLock serializes messages of a conversation. It is removed once no
+// message holds or waits for it.
+type dialogLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Conversation routes messages to the handler of the current conversation
+// state of each user and chat pair. A conversation is started with Start,
+// usually from a command handler, and moves between states with Dialog.Next
+// until Dialog.End is called. Idle conversation expires after Timeout, and the
+// user can abort it with the CancelCommand. Cancel command explicitly
+// addressed to a bot other than Username is ignored. Messages outside of an
+// active conversation are passed to the fallback handler. Messages from the
+// same user and chat are handled one at a time. Register all states and set
+// the fields before serving updates.
+type Conversation struct {
+	Timeout       time.Duration
+	CancelCommand string
+	Username      string
+	states        map[State]StateHandlerFunc
+	cancel        StateHandlerFunc
+	timeout       StateHandlerFunc
+	fallback      MessageHandlerFunc
+	mu            sync.Mutex
+	dialogs       map[dialogKey]*Dialog
+	locks         map[dialogKey]*dialogLock
+	swept         time.Time
+}
+
+// NewConversation creates new conversation with default timeout and cancel
+// command.
+func NewConversation() *Conversation {
+	return &Conversation{
+		Timeout:       DefaultConversationTimeout,
+		CancelCommand: DefaultCancelCommand,
+		states:        make(map[State]StateHandlerFunc),
+		dialogs:       make(map[dialogKey]*Dialog),
+		locks:         make(map[dialogKey]*dialogLock),
+	}
+}
+
+// Handle registers fn for messages in state.
+func (c *Conversation) Handle(state State, fn StateHandlerFunc) {
+	c.states[state] = fn
+}
+
+// HandleCancel registers fn called when the user aborts the conversation.
+func (c *Conversation) HandleCancel(fn StateHandlerFunc) {
+	c.cancel = fn
+}
+
+// HandleTimeout registers fn called when the user sends a message after the
+// conversation expired. The message is then passed to the fallback handler.
+func (c *Conversation) HandleTimeout(fn StateHandlerFunc) {
+	c.timeout = fn
+}
+
+// HandleFallback registers fn for messages outside of an active conversation.
+func (c *Conversation) HandleFallback(fn MessageHandlerFunc) {
+	c.fallback = fn
+}
+
+// Start begins new conversation in state for user in chat, replacing the
+// active one if any.
+func (c *Conversation) Start(chatID, userID int64, state State) *Dialog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.sweep(now)
+	d := &Dialog{
+		ChatID:    chatID,
+		UserID:    userID,
+		State:     state,
+		Data:      make(map[string]interface{}),
+		UpdatedAt: now,
+	}
+	c.dialogs[dialogKey{chatID, userID}] = d
+	return d
+}
+
+// Stop aborts the active conversation of user in chat.
+func (c *Conversation) Stop(chatID, userID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.dialogs, dialogKey{chatID, userID})
+}
+
+// Active gets the active conversation of user in chat or nil if there is none.
+func (c *Conversation) Active(chatID, userID int64) *Dialog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := c.dialogs[dialogKey{chatID, userID}]
+	if d == nil || c.expired(d, time.Now()) {
+		return nil
+	}
+	return d
+}
+
+// expired reports whether the conversation is idle for longer than timeout.
+func (c *Conversation) expired(d *Dialog, now time.Time) bool {
+	return c.Timeout > 0 && now.Sub(d.UpdatedAt) > c.Timeout
+}
+
+// sweep removes expired conversations. Must be called with lock held.
+func (c *Conversation) sweep(now time.Time) {
+	if c.Timeout <= 0 || now.Sub(c.swept) < c.Timeout {
+		return
+	}
+	for key, d := range c.dialogs {
+		if c.expired(d, now) {
+			delete(c.dialogs, key)
+		}
+	}
+	c.swept = now
+}
+
+// lock waits until no other message of conversation key is being handled and
+// returns the function to release it.
+func (c *Conversation) lock(key dialogKey) func() {
+	c.mu.Lock()
+	l, ok := c.locks[key]
+	if !ok {
+		l = &dialogLock{}
+		c.locks[key] = l
+	}
+	l.refs++
+	c.mu.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		c.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(c.locks, key)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// ServeMessage passes msg to the handler of the current conversation state.
+// It can be registered with Router.HandleMessage.
+func (c *Conversation) ServeMessage(ctx context.Context, msg *Message) error {
+	if msg.Chat == nil || msg.From == nil {
+		return c.serveFallback(ctx, msg)
+	}
+	key := dialogKey{msg.Chat.ID, msg.From.ID}
+	defer c.lock(key)()
+	// Look up the active conversation
+	c.mu.Lock()
+	now := time.Now()
+	d := c.dialogs[key]
+	expired := d != nil && c.expired(d, now)
+	if expired {
+		delete(c.dialogs, key)
+	}
+	c.mu.Unlock()
+	if d == nil {
+		return c.serveFallback(ctx, msg)
+	}
+	if expired {
+		if c.timeout != nil {
+			if err := c.timeout(ctx, d, msg); err != nil {
+				return err
+			}
+		}
+		return c.serveFallback(ctx, msg)
+	}
+	// Abort conversation on cancel command
+	if c.isCancel(msg) {
+		c.Stop(key.chatID, key.userID)
+		if c.cancel != nil {
+			return c.cancel(ctx, d, msg)
+		}
+		return nil
+	}
+	fn, ok := c.states[d.State]
+	if !ok {
+		return c.serveFallback(ctx, msg)
+	}
+	// Run state handler and apply the transition
+	d.next, d.ended = d.State, false
+	err := fn(ctx, d, msg)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Skip transition if the conversation was replaced or stopped
+	if c.dialogs[key] != d {
+		return err
+	}
+	if d.ended {
+		delete(c.dialogs, key)
+	} else {
+		d.State, d.UpdatedAt = d.next, time.Now()
+	}
+	return err
+}
+
+// isCancel reports whether msg is the cancel command for this bot.
+func (c *Conversation) isCancel(msg *Message) bool {
+	cmd, ok := ParseCommand(msg)
+	if !ok || len(c.CancelCommand) == 0 || cmd.Name != c.CancelCommand {
+		return false
+	}
+	// Ignore command for other bots
+	return len(cmd.Username) == 0 || strings.EqualFold(cmd.Username, c.Username)
+}
+
+// serveFallback passes msg to the fallback handler if set.
+func (c *Conversation) serveFallback(ctx context.Context, msg *Message) error {
+	if c.fallback != nil {
+		return c.fallback(ctx, msg)
+	}
+	return nil
+}
+
+// ServeUpdate implements the Handler interface. Only new messages are
+// dispatched.
+func (c *Conversation) ServeUpdate(ctx context.Context, upd *Update) error {
+	if upd.Message == nil {
+		return nil
+	}
+	return c.ServeMessage(ctx, upd.Message)
+}
@@ -0,0 +1,89 @@
+package telebot_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/adzil/telebot"
+)
+
+func newConversationMessage(text string) *telebot.Message {
+	msg := &telebot.Message{
+		Chat: &telebot.Chat{ID: -1, Type: telebot.GroupChat},
+		From: &telebot.User{ID: 1},
+		Text: text,
+	}
+	if len(text) > 0 && text[0] == '/' {
+		msg.Entities = []*telebot.MessageEntity{{Type: telebot.BotCommandEntity, Length: len(text)}}
+	}
+	return msg
+}
+
+func TestConversationConcurrentMessages(t *testing.T) {
+	c := telebot.NewConversation()
+	c.Handle("count", func(ctx context.Context, d *telebot.Dialog, msg *telebot.Message) error {
+		n, _ := d.Get("n").(int)
+		d.Set("n", n+1)
+		d.Next("count")
+		return nil
+	})
+	c.Start(-1, 1, "count")
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.ServeMessage(context.Background(), newConversationMessage("hi")); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	d := c.Active(-1, 1)
+	if d == nil {
+		t.Fatal("conversation is not active")
+	}
+	if n := d.Get("n"); n != 50 {
+		t.Fatalf("handled %v messages, want 50", n)
+	}
+}
+
+func TestConversationCancelCommand(t *testing.T) {
+	c := telebot.NewConversation()
+	c.Username = "test_bot"
+	var states []string
+	c.Handle("wait", func(ctx context.Context, d *telebot.Dialog, msg *telebot.Message) error {
+		states = append(states, msg.Text)
+		d.Next("wait")
+		return nil
+	})
+	cancelled := 0
+	c.HandleCancel(func(ctx context.Context, d *telebot.Dialog, msg *telebot.Message) error {
+		cancelled++
+		return nil
+	})
+	tests := []struct {
+		text   string
+		cancel bool
+	}{
+		{"/cancel@other_bot", false},
+		{"/cancel@Test_Bot", true},
+		{"/cancel", true},
+	}
+	for _, tt := range tests {
+		c.Start(-1, 1, "wait")
+		cancelled, states = 0, nil
+		if err := c.ServeMessage(context.Background(), newConversationMessage(tt.text)); err != nil {
+			t.Fatal(err)
+		}
+		active := c.Active(-1, 1) != nil
+		if active == tt.cancel || (cancelled == 1) != tt.cancel {
+			t.Errorf("%s: active = %v, cancelled = %d, want cancel %v", tt.text, active, cancelled, tt.cancel)
+		}
+		// Command for other bot is handled as a regular message
+		if !tt.cancel && (len(states) != 1 || states[0] != tt.text) {
+			t.Errorf("%s: state handler got %q", tt.text, states)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package telebot
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// DefaultWorkers used to set the number of dispatcher workers.
+	DefaultWorkers = 8
+
+	// DefaultQueueSize used to set the dispatcher queue capacity.
+	DefaultQueueSize = 256
+)
+
+// dispatchJob represents an update waiting in the dispatcher queue.
+type dispatchJob struct {
+	ctx context.Context
+	upd *Update
+	ack func()
+}
+
+// dispatchQueue holds queued updates of a chat in order.
+type dispatchQueue struct {
+	key  int64
+	jobs []*dispatchJob
+}
+
+// Dispatcher implements Handler that processes updates concurrently with a
+// pool of workers while keeping updates from the same chat, or the same user
+// if the update has no chat, in order. Updates wait in per-chat queues that
+// are handed to free workers, sharing a bounded capacity, so ServeUpdate
+// blocks when the queue is full to apply backpressure to the poller while a
+// slow chat does not hold up the others. Updates are acknowledged only after
+// they are processed. Handlers run with a context detached from the
+// cancellation of the one passed to ServeUpdate, so they outlive the webhook
+// request, but keep its values. ErrorHandler receives errors from the handler
+// like Bot.ErrorHandler.
+type Dispatcher struct {
+	ErrorHandler func(err error)
+	h            Handler
+	slots        chan struct{}
+	ready        chan *dispatchQueue
+	mu           sync.Mutex
+	queues       map[int64]*dispatchQueue
+	pending      sync.WaitGroup
+	wg           sync.WaitGroup
+}
+
+// NewDispatcher creates new dispatcher and starts its workers. Up to queueSize
+// updates across all chats can wait to be processed. If workers or queueSize
+// is not positive, it will be set to DefaultWorkers or DefaultQueueSize
+// respectively.
+func NewDispatcher(h Handler, workers, queueSize int) *Dispatcher {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	d := &Dispatcher{
+		h:      h,
+		slots:  make(chan struct{}, queueSize),
+		ready:  make(chan *dispatchQueue, queueSize),
+		queues: make(map[int64]*dispatchQueue),
+	}
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// work takes one update at a time from chat queues that are ready. A chat
+// queue is handed to one worker at a time, so its updates are processed in
+// order.
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for q := range d.ready {
+		// Take the next update and free its queue slot
+		d.mu.Lock()
+		job := q.jobs[0]
+		q.jobs[0] = nil
+		q.jobs = q.jobs[1:]
+		d.mu.Unlock()
+		<-d.slots
+		d.serve(job)
+		// Hand the chat queue back or forget it if it is empty
+		d.mu.Lock()
+		if len(q.jobs) > 0 {
+			d.ready <- q
+		} else {
+			delete(d.queues, q.key)
+		}
+		d.mu.Unlock()
+		d.pending.Done()
+	}
+}
+
+// serve passes queued update to the handler.
+func (d *Dispatcher) serve(job *dispatchJob) {
+	// Let the handler defer the acknowledgement further
+	ack := &deferredAck{fn: job.ack}
+	err := d.h.ServeUpdate(context.WithValue(job.ctx, ackKey{}, ack), job.upd)
+	if err != nil {
+		reportError(d.ErrorHandler, err)
+	}
+	if !ack.deferred {
+		ack.ack()
+	}
+}
+
+// dispatchKey returns the ordering key of update.
+func dispatchKey(upd *Update) int64 {
+	if chat := upd.Chat(); chat != nil {
+		return chat.ID
+	} else if from := upd.From(); from != nil {
+		return from.ID
+	}
+	return upd.ID
+}
+
+// ServeUpdate implements the Handler interface. It blocks until the update is
+// queued or ctx is cancelled.
+func (d *Dispatcher) ServeUpdate(ctx context.Context, upd *Update) error {
+	// Wait for free queue slot
+	select {
+	case d.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	job := &dispatchJob{ctx: detach(ctx), upd: upd, ack: DeferAck(ctx)}
+	d.pending.Add(1)
+	// Append to the chat queue and hand it to a worker if it is idle. The
+	// ready channel never blocks because every chat queue in it holds at
+	// least one slot.
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := dispatchKey(upd)
+	q, ok := d.queues[key]
+	if !ok {
+		q = &dispatchQueue{key: key}
+		d.queues[key] = q
+	}
+	q.jobs = append(q.jobs, job)
+	if !ok {
+		d.ready <- q
+	}
+	return nil
+}
+
+// QueueDepth returns the number of updates waiting to be processed.
+func (d *Dispatcher) QueueDepth() int {
+	return len(d.slots)
+}
+
+// Close stops accepting updates and waits until all queued updates are
+// processed. Do not call ServeUpdate after or concurrently with Close.
+func (d *Dispatcher) Close() {
+	d.pending.Wait()
+	close(d.ready)
+	d.wg.Wait()
+}
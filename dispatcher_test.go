@@ -0,0 +1,147 @@
+package telebot_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adzil/telebot"
+)
+
+func newChatUpdate(id, chatID int64) *telebot.Update {
+	return &telebot.Update{
+		ID:      id,
+		Message: &telebot.Message{ID: id, Chat: &telebot.Chat{ID: chatID}},
+	}
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[int64][]int64)
+	d := telebot.NewDispatcher(telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+		// Slow down one chat so other chats overtake it
+		if upd.Message.Chat.ID == 1 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		seen[upd.Message.Chat.ID] = append(seen[upd.Message.Chat.ID], upd.ID)
+		mu.Unlock()
+		return nil
+	}), 4, 8)
+	id := int64(0)
+	for i := 0; i < 20; i++ {
+		for chatID := int64(1); chatID <= 5; chatID++ {
+			id++
+			if err := d.ServeUpdate(context.Background(), newChatUpdate(id, chatID)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	d.Close()
+	for chatID := int64(1); chatID <= 5; chatID++ {
+		ids := seen[chatID]
+		if len(ids) != 20 {
+			t.Fatalf("chat %d got %d updates, want 20", chatID, len(ids))
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] <= ids[i-1] {
+				t.Fatalf("chat %d got updates out of order: %v", chatID, ids)
+			}
+		}
+	}
+}
+
+func TestDispatcherBackpressure(t *testing.T) {
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	d := telebot.NewDispatcher(telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}), 1, 1)
+	defer d.Close()
+	// Block the only worker and fill its queue
+	if err := d.ServeUpdate(context.Background(), newChatUpdate(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := d.ServeUpdate(context.Background(), newChatUpdate(2, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.QueueDepth(); n != 1 {
+		t.Fatalf("queue depth = %d, want 1", n)
+	}
+	// Full queue blocks until ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := d.ServeUpdate(ctx, newChatUpdate(3, 1)); err != context.DeadlineExceeded {
+		t.Fatalf("ServeUpdate returned %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(release)
+}
+
+func TestDispatcherSlowChat(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan int64, 100)
+	d := telebot.NewDispatcher(telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+		if upd.Message.Chat.ID == 1 {
+			<-release
+		}
+		done <- upd.ID
+		return nil
+	}), 2, 4)
+	// Block one worker and queue more updates behind it
+	for id := int64(1); id <= 3; id++ {
+		if err := d.ServeUpdate(context.Background(), newChatUpdate(id, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Other chats keep flowing through the free worker
+	for id := int64(4); id <= 50; id++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := d.ServeUpdate(ctx, newChatUpdate(id, 2+id%5))
+		cancel()
+		if err != nil {
+			t.Fatalf("update %d blocked behind slow chat: %v", id, err)
+		}
+	}
+	for i := 0; i < 47; i++ {
+		if id := <-done; id <= 3 {
+			t.Fatalf("slow chat update %d processed before release", id)
+		}
+	}
+	close(release)
+	d.Close()
+	for want := int64(1); want <= 3; want++ {
+		if id := <-done; id != want {
+			t.Fatalf("slow chat got update %d, want %d", id, want)
+		}
+	}
+}
+
+type testKey struct{}
+
+func TestDispatcherDetachedContext(t *testing.T) {
+	release := make(chan struct{})
+	var value interface{}
+	var err error
+	d := telebot.NewDispatcher(telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+		<-release
+		value, err = ctx.Value(testKey{}), ctx.Err()
+		return nil
+	}), 1, 1)
+	// Cancel the request context before the update is processed
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), testKey{}, "value"))
+	if err := d.ServeUpdate(ctx, newChatUpdate(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	close(release)
+	d.Close()
+	if err != nil {
+		t.Fatalf("handler context error = %v, want nil", err)
+	}
+	if value != "value" {
+		t.Fatalf("handler context value = %v, want %q", value, "value")
+	}
+}
@@ -0,0 +1,569 @@
+package telebot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FakeCall represents a method call recorded by FakeBot.
+type FakeCall struct {
+	Method  string
+	Request interface{}
+}
+
+// FakeBot implements the API interface without any network call. It records
+// every call and returns scripted results, so it can be used to unit test
+// application handlers. Send returns the queued messages in order, or a
+// message echoing the request chat when the queue is empty. It is safe for
+// concurrent use.
+type FakeBot struct {
+	Self     *User
+	mu       sync.Mutex
+	calls    []FakeCall
+	sent     []SendRequest
+	messages []*Message
+	errors   map[string][]error
+	updates  []*Update
+	webhook  WebhookInfo
+	files    map[string][]byte
+	chats    map[int64]*Chat
+	members  map[int64][]*ChatMember
+	lastID   int64
+}
+
+// NewFakeBot creates new fake bot with placeholder bot information.
+func NewFakeBot() *FakeBot {
+	return &FakeBot{
+		Self: &User{
+			ID:        1,
+			IsBot:     true,
+			FirstName: "Fake",
+			Username:  "fake_bot",
+		},
+	}
+}
+
+// QueueMessage adds message to be returned by the next unscripted Send call.
+func (f *FakeBot) QueueMessage(msgs ...*Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, msgs...)
+}
+
+// QueueError makes the next call of method name returns err. For send
+// requests, use the SendRequestType value as the method name.
+func (f *FakeBot) QueueError(name string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.errors == nil {
+		f.errors = make(map[string][]error)
+	}
+	f.errors[name] = append(f.errors[name], err)
+}
+
+// QueueUpdates adds updates to be returned by the next GetUpdates call.
+func (f *FakeBot) QueueUpdates(upds ...*Update) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, upds...)
+}
+
+// AddFile stores file contents to be returned by GetFile and DownloadFile.
+func (f *FakeBot) AddFile(fileID string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.files == nil {
+		f.files = make(map[string][]byte)
+	}
+	f.files[fileID] = data
+}
+
+// AddChat stores chat to be returned by GetChat.
+func (f *FakeBot) AddChat(chat *Chat) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.chats == nil {
+		f.chats = make(map[int64]*Chat)
+	}
+	f.chats[chat.ID] = chat
+}
+
+// AddChatMember stores member of chatID to be returned by the chat member
+// methods. Chat administration methods update the stored members.
+func (f *FakeBot) AddChatMember(chatID int64, member *ChatMember) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.members == nil {
+		f.members = make(map[int64][]*ChatMember)
+	}
+	f.members[chatID] = append(f.members[chatID], member)
+}
+
+// Calls returns all recorded calls in order.
+func (f *FakeBot) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeCall(nil), f.calls...)
+}
+
+// Sent returns all recorded send requests in order.
+func (f *FakeBot) Sent() []SendRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]SendRequest(nil), f.sent...)
+}
+
+// Reset removes all recorded calls and scripted results.
+func (f *FakeBot) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls, f.sent, f.messages, f.errors, f.updates = nil, nil, nil, nil, nil
+	f.files, f.chats, f.members = nil, nil, nil
+}
+
+// record stores the call and pops the scripted error. Must be called with lock
+// held.
+func (f *FakeBot) record(name string, req interface{}) error {
+	f.calls = append(f.calls, FakeCall{Method: name, Request: req})
+	if errs := f.errors[name]; len(errs) > 0 {
+		f.errors[name] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+// GetUpdates implements the API interface.
+func (f *FakeBot) GetUpdates(req *GetUpdates) ([]*Update, error) {
+	return f.GetUpdatesContext(context.Background(), req)
+}
+
+// GetUpdatesContext implements the API interface.
+func (f *FakeBot) GetUpdatesContext(ctx context.Context, req *GetUpdates) ([]*Update, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getUpdates", req); err != nil {
+		return nil, err
+	}
+	// Drop confirmed updates and return the rest within limit
+	var upds []*Update
+	for _, upd := range f.updates {
+		if upd.ID >= req.Offset {
+			upds = append(upds, upd)
+		}
+	}
+	f.updates = upds
+	if req.Limit > 0 && len(upds) > req.Limit {
+		upds = upds[:req.Limit]
+	}
+	return append([]*Update(nil), upds...), nil
+}
+
+// SetWebhook implements the API interface.
+func (f *FakeBot) SetWebhook(req *SetWebhook) error {
+	return f.SetWebhookContext(context.Background(), req)
+}
+
+// SetWebhookContext implements the API interface.
+func (f *FakeBot) SetWebhookContext(ctx context.Context, req *SetWebhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("setWebhook", req); err != nil {
+		return err
+	}
+	f.webhook = WebhookInfo{
+		URL:                  req.URL,
+		HasCustomCertificate: req.Certificate != nil,
+		MaxConnections:       req.MaxConnections,
+	}
+	for _, typ := range req.AllowedUpdates {
+		f.webhook.AllowedUpdates = append(f.webhook.AllowedUpdates, string(typ))
+	}
+	return nil
+}
+
+// DeleteWebhook implements the API interface.
+func (f *FakeBot) DeleteWebhook() (bool, error) {
+	return f.DeleteWebhookContext(context.Background())
+}
+
+// DeleteWebhookContext implements the API interface.
+func (f *FakeBot) DeleteWebhookContext(ctx context.Context) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("deleteWebhook", nil); err != nil {
+		return false, err
+	}
+	f.webhook = WebhookInfo{}
+	return true, nil
+}
+
+// GetWebhookInfo implements the API interface.
+func (f *FakeBot) GetWebhookInfo() (*WebhookInfo, error) {
+	return f.GetWebhookInfoContext(context.Background())
+}
+
+// GetWebhookInfoContext implements the API interface.
+func (f *FakeBot) GetWebhookInfoContext(ctx context.Context) (*WebhookInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getWebhookInfo", nil); err != nil {
+		return nil, err
+	}
+	info := f.webhook
+	return &info, nil
+}
+
+// GetMe implements the API interface.
+func (f *FakeBot) GetMe() (*User, error) {
+	return f.GetMeContext(context.Background())
+}
+
+// GetMeContext implements the API interface.
+func (f *FakeBot) GetMeContext(ctx context.Context) (*User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getMe", nil); err != nil {
+		return nil, err
+	}
+	return f.Self, nil
+}
+
+// Send implements the API interface.
+func (f *FakeBot) Send(req SendRequest) (*Message, error) {
+	return f.SendContext(context.Background(), req)
+}
+
+// SendContext implements the API interface.
+func (f *FakeBot) SendContext(ctx context.Context, req SendRequest) (*Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, req)
+	if err := f.record(string(req.Type()), req); err != nil {
+		return nil, err
+	}
+	// Return scripted message if any
+	if len(f.messages) > 0 {
+		msg := f.messages[0]
+		f.messages = f.messages[1:]
+		return msg, nil
+	}
+	// Create message echoing the request
+	f.lastID++
+	msg := &Message{
+		ID:   f.lastID,
+		From: f.Self,
+		Date: time.Now().Unix(),
+		Chat: &Chat{ID: chatIDOf(req)},
+	}
+	if m, ok := req.(*SendMessage); ok {
+		msg.Text = m.Text
+	}
+	return msg, nil
+}
+
+// SendMediaGroup implements the API interface.
+func (f *FakeBot) SendMediaGroup(req *SendMediaGroup) ([]*Message, error) {
+	return f.SendMediaGroupContext(context.Background(), req)
+}
+
+// SendMediaGroupContext implements the API interface. It returns one message
+// echoing the request chat for each media.
+func (f *FakeBot) SendMediaGroupContext(ctx context.Context, req *SendMediaGroup) ([]*Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("sendMediaGroup", req); err != nil {
+		return nil, err
+	}
+	groupID := strconv.FormatInt(f.lastID+1, 10)
+	msgs := make([]*Message, len(req.Media))
+	for i := range req.Media {
+		f.lastID++
+		msgs[i] = &Message{
+			ID:           f.lastID,
+			From:         f.Self,
+			Date:         time.Now().Unix(),
+			Chat:         &Chat{ID: req.ChatID},
+			MediaGroupID: groupID,
+		}
+	}
+	return msgs, nil
+}
+
+// AnswerCallbackQuery implements the API interface.
+func (f *FakeBot) AnswerCallbackQuery(req *AnswerCallbackQuery) (bool, error) {
+	return f.AnswerCallbackQueryContext(context.Background(), req)
+}
+
+// AnswerCallbackQueryContext implements the API interface.
+func (f *FakeBot) AnswerCallbackQueryContext(ctx context.Context, req *AnswerCallbackQuery) (bool, error) {
+	return f.answer("answerCallbackQuery", req)
+}
+
+// DeleteMessage implements the API interface.
+func (f *FakeBot) DeleteMessage(req *DeleteMessage) (bool, error) {
+	return f.DeleteMessageContext(context.Background(), req)
+}
+
+// DeleteMessageContext implements the API interface.
+func (f *FakeBot) DeleteMessageContext(ctx context.Context, req *DeleteMessage) (bool, error) {
+	return f.answer("deleteMessage", req)
+}
+
+// AnswerInlineQuery implements the API interface.
+func (f *FakeBot) AnswerInlineQuery(req *AnswerInlineQuery) (bool, error) {
+	return f.AnswerInlineQueryContext(context.Background(), req)
+}
+
+// AnswerInlineQueryContext implements the API interface.
+func (f *FakeBot) AnswerInlineQueryContext(ctx context.Context, req *AnswerInlineQuery) (bool, error) {
+	return f.answer("answerInlineQuery", req)
+}
+
+// GetFile implements the API interface.
+func (f *FakeBot) GetFile(req *GetFile) (*File, error) {
+	return f.GetFileContext(context.Background(), req)
+}
+
+// GetFileContext implements the API interface. It returns error for files not
+// added with AddFile.
+func (f *FakeBot) GetFileContext(ctx context.Context, req *GetFile) (*File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getFile", req); err != nil {
+		return nil, err
+	}
+	return f.file(req.FileID)
+}
+
+// DownloadFile implements the API interface. It returns error for files not
+// added with AddFile.
+func (f *FakeBot) DownloadFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("downloadFile", fileID); err != nil {
+		return nil, err
+	}
+	if _, err := f.file(fileID); err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(f.files[fileID])), nil
+}
+
+// file looks up file added with AddFile. Must be called with lock held.
+func (f *FakeBot) file(fileID string) (*File, error) {
+	data, ok := f.files[fileID]
+	if !ok {
+		return nil, &Error{
+			HTTPCode:    http.StatusBadRequest,
+			ErrorCode:   http.StatusBadRequest,
+			Description: "Bad Request: invalid file_id",
+		}
+	}
+	return &File{
+		FileID:       fileID,
+		FileUniqueID: fileID,
+		FileSize:     int64(len(data)),
+		FilePath:     "files/" + fileID,
+	}, nil
+}
+
+// GetChat implements the API interface. It returns chat added with AddChat
+// or a private chat with the identifier.
+func (f *FakeBot) GetChat(req *GetChat) (*Chat, error) {
+	return f.GetChatContext(context.Background(), req)
+}
+
+// GetChatContext implements the API interface.
+func (f *FakeBot) GetChatContext(ctx context.Context, req *GetChat) (*Chat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getChat", req); err != nil {
+		return nil, err
+	}
+	if chat, ok := f.chats[req.ChatID]; ok {
+		return chat, nil
+	}
+	return &Chat{ID: req.ChatID, Type: PrivateChat}, nil
+}
+
+// GetChatAdministrators implements the API interface.
+func (f *FakeBot) GetChatAdministrators(req *GetChatAdministrators) ([]*ChatMember, error) {
+	return f.GetChatAdministratorsContext(context.Background(), req)
+}
+
+// GetChatAdministratorsContext implements the API interface.
+func (f *FakeBot) GetChatAdministratorsContext(ctx context.Context, req *GetChatAdministrators) ([]*ChatMember, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getChatAdministrators", req); err != nil {
+		return nil, err
+	}
+	var admins []*ChatMember
+	for _, member := range f.members[req.ChatID] {
+		if member.Status == CreatorMember || member.Status == AdministratorMember {
+			admins = append(admins, member)
+		}
+	}
+	return admins, nil
+}
+
+// GetChatMember implements the API interface. Unknown user is reported as a
+// member who left the chat.
+func (f *FakeBot) GetChatMember(req *GetChatMember) (*ChatMember, error) {
+	return f.GetChatMemberContext(context.Background(), req)
+}
+
+// GetChatMemberContext implements the API interface.
+func (f *FakeBot) GetChatMemberContext(ctx context.Context, req *GetChatMember) (*ChatMember, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getChatMember", req); err != nil {
+		return nil, err
+	}
+	return f.member(req.ChatID, req.UserID), nil
+}
+
+// GetChatMemberCount implements the API interface.
+func (f *FakeBot) GetChatMemberCount(req *GetChatMemberCount) (int, error) {
+	return f.GetChatMemberCountContext(context.Background(), req)
+}
+
+// GetChatMemberCountContext implements the API interface.
+func (f *FakeBot) GetChatMemberCountContext(ctx context.Context, req *GetChatMemberCount) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("getChatMemberCount", req); err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, member := range f.members[req.ChatID] {
+		if member.Status != LeftMember && member.Status != KickedMember {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// BanChatMember implements the API interface.
+func (f *FakeBot) BanChatMember(req *BanChatMember) (bool, error) {
+	return f.BanChatMemberContext(context.Background(), req)
+}
+
+// BanChatMemberContext implements the API interface.
+func (f *FakeBot) BanChatMemberContext(ctx context.Context, req *BanChatMember) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("banChatMember", req); err != nil {
+		return false, err
+	}
+	member := f.member(req.ChatID, req.UserID)
+	member.Status, member.UntilDate = KickedMember, req.UntilDate
+	return true, nil
+}
+
+// UnbanChatMember implements the API interface.
+func (f *FakeBot) UnbanChatMember(req *UnbanChatMember) (bool, error) {
+	return f.UnbanChatMemberContext(context.Background(), req)
+}
+
+// UnbanChatMemberContext implements the API interface.
+func (f *FakeBot) UnbanChatMemberContext(ctx context.Context, req *UnbanChatMember) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("unbanChatMember", req); err != nil {
+		return false, err
+	}
+	member := f.member(req.ChatID, req.UserID)
+	if !req.OnlyIfBanned || member.Status == KickedMember {
+		member.Status, member.UntilDate = LeftMember, 0
+	}
+	return true, nil
+}
+
+// RestrictChatMember implements the API interface.
+func (f *FakeBot) RestrictChatMember(req *RestrictChatMember) (bool, error) {
+	return f.RestrictChatMemberContext(context.Background(), req)
+}
+
+// RestrictChatMemberContext implements the API interface.
+func (f *FakeBot) RestrictChatMemberContext(ctx context.Context, req *RestrictChatMember) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("restrictChatMember", req); err != nil {
+		return false, err
+	}
+	perms := req.Permissions
+	member := f.member(req.ChatID, req.UserID)
+	member.Status, member.UntilDate = RestrictedMember, req.UntilDate
+	member.CanSendMessages = perms.CanSendMessages
+	member.CanSendMediaMessages = perms.CanSendMediaMessages
+	member.CanSendOtherMessages = perms.CanSendOtherMessages
+	member.CanAddWebPagePreviews = perms.CanAddWebPagePreviews
+	member.CanChangeInfo = perms.CanChangeInfo
+	member.CanInviteUsers = perms.CanInviteUsers
+	member.CanPinMessages = perms.CanPinMessages
+	return true, nil
+}
+
+// PromoteChatMember implements the API interface.
+func (f *FakeBot) PromoteChatMember(req *PromoteChatMember) (bool, error) {
+	return f.PromoteChatMemberContext(context.Background(), req)
+}
+
+// PromoteChatMemberContext implements the API interface.
+func (f *FakeBot) PromoteChatMemberContext(ctx context.Context, req *PromoteChatMember) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record("promoteChatMember", req); err != nil {
+		return false, err
+	}
+	member := f.member(req.ChatID, req.UserID)
+	member.Status = AdministratorMember
+	member.CanChangeInfo = req.CanChangeInfo
+	member.CanPostMessages = req.CanPostMessages
+	member.CanEditMessages = req.CanEditMessages
+	member.CanDeleteMessages = req.CanDeleteMessages
+	member.CanInviteUsers = req.CanInviteUsers
+	member.CanRestrictMembers = req.CanRestrictMembers
+	member.CanPinMessages = req.CanPinMessages
+	member.CanPromoteMembers = req.CanPromoteMembers
+	// Demote user without any rights
+	if !req.CanChangeInfo && !req.CanPostMessages && !req.CanEditMessages &&
+		!req.CanDeleteMessages && !req.CanInviteUsers && !req.CanRestrictMembers &&
+		!req.CanPinMessages && !req.CanPromoteMembers {
+		member.Status = Member
+	}
+	return true, nil
+}
+
+// member gets member of chat or stores a new one that left the chat if it does
+// not exist. Must be called with lock held.
+func (f *FakeBot) member(chatID, userID int64) *ChatMember {
+	for _, member := range f.members[chatID] {
+		if member.User != nil && member.User.ID == userID {
+			return member
+		}
+	}
+	if f.members == nil {
+		f.members = make(map[int64][]*ChatMember)
+	}
+	member := &ChatMember{User: &User{ID: userID}, Status: LeftMember}
+	f.members[chatID] = append(f.members[chatID], member)
+	return member
+}
+
+// answer records boolean returning method call.
+func (f *FakeBot) answer(name string, req interface{}) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.record(name, req); err != nil {
+		return false, err
+	}
+	return true, nil
+}
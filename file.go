@@ -0,0 +1,269 @@
+package telebot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InputFile represents the contents of a file to be sent. It can be a file_id
+// of a file that already exists on the Telegram servers, an HTTP URL for
+// Telegram to get the file from the Internet, or a new file to be uploaded
+// using multipart/form-data. Use exactly one of FileID, URL or Reader.
+type InputFile struct {
+	FileID string
+	URL    string
+	Name   string
+	Reader io.Reader
+	attach string
+}
+
+// NewInputFileID is a helper function to instantiate input file from existing
+// file_id.
+func NewInputFileID(fileID string) *InputFile {
+	return &InputFile{FileID: fileID}
+}
+
+// NewInputFileURL is a helper function to instantiate input file from an HTTP
+// URL.
+func NewInputFileURL(url string) *InputFile {
+	return &InputFile{URL: url}
+}
+
+// NewInputFileReader is a helper function to instantiate input file to be
+// uploaded from a reader. If r implements io.Closer, it will be closed after
+// the upload.
+func NewInputFileReader(name string, r io.Reader) *InputFile {
+	return &InputFile{Name: name, Reader: r}
+}
+
+// OpenInputFile opens a local file to be uploaded. The file will be closed
+// after the upload.
+func OpenInputFile(path string) (*InputFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewInputFileReader(filepath.Base(path), f), nil
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (f *InputFile) MarshalJSON() ([]byte, error) {
+	if f.Reader != nil {
+		return json.Marshal("attach://" + f.attach)
+	} else if len(f.FileID) > 0 {
+		return json.Marshal(f.FileID)
+	}
+	return json.Marshal(f.URL)
+}
+
+// uploadFiles collects input files from request that need to be uploaded,
+// keyed by their form field name taken from the json tag. Files inside slices
+// of attachments are keyed by generated names referenced with attach://.
+func uploadFiles(request interface{}) map[string]*InputFile {
+	// Dereference request into struct value
+	v := reflect.ValueOf(request)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	// Iterate over exported fields to find input files with reader
+	var files map[string]*InputFile
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Slice {
+			// Collect files nested inside attachments such as input media
+			for j := 0; j < fv.Len(); j++ {
+				a, ok := fv.Index(j).Interface().(attachments)
+				if !ok || reflect.ValueOf(a).IsNil() {
+					continue
+				}
+				for _, file := range a.inputFiles() {
+					if file == nil || file.Reader == nil {
+						continue
+					}
+					if files == nil {
+						files = make(map[string]*InputFile)
+					}
+					name := "file" + strconv.Itoa(len(files))
+					file.attach = name
+					files[name] = file
+				}
+			}
+			continue
+		}
+		file, ok := fv.Interface().(*InputFile)
+		if !ok || file == nil || file.Reader == nil {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		if files == nil {
+			files = make(map[string]*InputFile)
+		}
+		file.attach = name
+		files[name] = file
+	}
+	return files
+}
+
+// writeMultipart encodes request as multipart form fields and streams the
+// files into w.
+func writeMultipart(w *multipart.Writer, request interface{}, files map[string]*InputFile) error {
+	// Close all readers whatever the outcome is
+	defer func() {
+		for _, file := range files {
+			if c, ok := file.Reader.(io.Closer); ok {
+				c.Close()
+			}
+		}
+	}()
+	// Reuse JSON encoding to get the field names and values
+	buf, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(buf, &fields); err != nil {
+		return err
+	}
+	// Write fields in a stable order
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw := fields[name]
+		// Strings are sent unquoted while other values are sent as JSON
+		value := string(raw)
+		if value == "null" {
+			continue
+		} else if len(raw) > 0 && raw[0] == '"' {
+			if err = json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+		}
+		if err = w.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+	// Stream files
+	names = names[:0]
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file := files[name]
+		part, err := w.CreateFormFile(name, file.Name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(part, file.Reader); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// ErrFileTooLarge is returned when the downloaded file exceeds the size limit.
+var ErrFileTooLarge = errors.New("telebot: file is too large")
+
+// File represents a file ready to be downloaded. The file path is valid for at
+// least 1 hour after getFile is called.
+type File struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileSize     int64  `json:"file_size,omitempty"`
+	FilePath     string `json:"file_path,omitempty"`
+}
+
+// GetFile sets parameter for GetFile method.
+type GetFile struct {
+	FileID string `json:"file_id"`
+}
+
+// GetFile get basic information about a file and prepare it for downloading.
+// Bots can download files of up to 20MB in size from the Telegram servers.
+func (b *Bot) GetFile(req *GetFile) (*File, error) {
+	return b.GetFileContext(context.Background(), req)
+}
+
+// GetFileContext is like GetFile but with cancellation context.
+func (b *Bot) GetFileContext(ctx context.Context, req *GetFile) (*File, error) {
+	var file File
+	err := b.caller.CallContext(ctx, "getFile", req, &file)
+	return &file, err
+}
+
+// DownloadFile opens the contents of file fileID. Close the returned reader
+// when done.
+func (b *Bot) DownloadFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	file, err := b.GetFileContext(ctx, &GetFile{FileID: fileID})
+	if err != nil {
+		return nil, err
+	}
+	return b.caller.Download(ctx, file.FilePath)
+}
+
+// DownloadFileTo downloads the contents of file fileID into a local file at
+// path. If limit is positive, it returns ErrFileTooLarge without keeping the
+// file when the contents exceed limit bytes. The file is replaced atomically
+// once the download completes. It returns the number of bytes written.
+func (b *Bot) DownloadFileTo(ctx context.Context, fileID, path string, limit int64) (int64, error) {
+	file, err := b.GetFileContext(ctx, &GetFile{FileID: fileID})
+	if err != nil {
+		return 0, err
+	}
+	// Check reported size before downloading
+	if limit > 0 && file.FileSize > limit {
+		return 0, ErrFileTooLarge
+	}
+	r, err := b.caller.Download(ctx, file.FilePath)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	var n int64
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		var src io.Reader = r
+		if limit > 0 {
+			src = io.LimitReader(r, limit+1)
+		}
+		var err error
+		if n, err = io.Copy(w, src); err != nil {
+			return err
+		}
+		if limit > 0 && n > limit {
+			return ErrFileTooLarge
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
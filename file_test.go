@@ -0,0 +1,142 @@
+package telebot_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adzil/telebot"
+	"github.com/adzil/telebot/telebottest"
+)
+
+func TestDownloadFile(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.AddFile("doc", []byte("contents"))
+	r, err := bot.DownloadFile(context.Background(), "doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "contents" {
+		t.Fatalf("downloaded %q, want %q", data, "contents")
+	}
+	if _, err := bot.DownloadFile(context.Background(), "missing"); err == nil {
+		t.Fatal("DownloadFile succeeded for missing file")
+	}
+}
+
+func TestDownloadFileTo(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.AddFile("doc", []byte("contents"))
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	n, err := bot.DownloadFileTo(context.Background(), "doc", path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 8 || string(data) != "contents" {
+		t.Fatalf("downloaded %d bytes %q, want 8 bytes %q", n, data, "contents")
+	}
+}
+
+func TestDownloadFileToLimit(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.AddFile("big", []byte("too large contents"))
+	tests := []struct {
+		name string
+		size int64
+	}{
+		{"reported size", 18},
+		{"unknown size", 0},
+	}
+	for _, tt := range tests {
+		// Report the file size so the limit is checked before or while
+		// downloading
+		size := tt.size
+		srv.Handle("getFile", func(call *telebottest.Call) (interface{}, error) {
+			return &telebot.File{FileID: "big", FileSize: size, FilePath: "files/big"}, nil
+		})
+		dir := t.TempDir()
+		path := filepath.Join(dir, "big.txt")
+		if err := ioutil.WriteFile(path, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bot.DownloadFileTo(context.Background(), "big", path, 8); err != telebot.ErrFileTooLarge {
+			t.Fatalf("%s: DownloadFileTo returned %v, want %v", tt.name, err, telebot.ErrFileTooLarge)
+		}
+		// Existing file is kept and no temporary file is left behind
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "old" {
+			t.Fatalf("%s: file replaced with %q", tt.name, data)
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("%s: got %d files in directory, want 1", tt.name, len(files))
+		}
+	}
+}
+
+func TestDownloadFileLocalMode(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot(telebot.WithLocalMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Local server returns absolute path on the same machine
+	local := filepath.Join(t.TempDir(), "local.txt")
+	if err := ioutil.WriteFile(local, []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv.Handle("getFile", func(call *telebottest.Call) (interface{}, error) {
+		return &telebot.File{FileID: "local", FileSize: 5, FilePath: local}, nil
+	})
+	path := filepath.Join(t.TempDir(), "copy.txt")
+	if _, err := bot.DownloadFileTo(context.Background(), "local", path, 0); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "local" {
+		t.Fatalf("downloaded %q, want %q", data, "local")
+	}
+	if len(srv.Calls()) == 0 || srv.Calls()[len(srv.Calls())-1].Method != "getFile" {
+		t.Fatal("file was not looked up with getFile")
+	}
+	os.Remove(local)
+	if _, err := bot.DownloadFile(context.Background(), "local"); !os.IsNotExist(err) {
+		t.Fatalf("DownloadFile returned %v, want not exist error", err)
+	}
+}
@@ -0,0 +1,170 @@
+package telebot
+
+import "regexp"
+
+// Filter reports whether an update should be handled. It can be used to
+// register routes with Router.HandleFilter or to gate handlers with Guard.
+type Filter func(upd *Update) bool
+
+// And creates filter that accepts updates accepted by all filters.
+func And(filters ...Filter) Filter {
+	return func(upd *Update) bool {
+		for _, f := range filters {
+			if !f(upd) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or creates filter that accepts updates accepted by any of the filters.
+func Or(filters ...Filter) Filter {
+	return func(upd *Update) bool {
+		for _, f := range filters {
+			if f(upd) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not creates filter that accepts updates rejected by f.
+func Not(f Filter) Filter {
+	return func(upd *Update) bool {
+		return !f(upd)
+	}
+}
+
+// message gets the message of new or edited message and channel post update.
+func message(upd *Update) *Message {
+	switch {
+	case upd.Message != nil:
+		return upd.Message
+	case upd.EditedMessage != nil:
+		return upd.EditedMessage
+	case upd.ChannelPost != nil:
+		return upd.ChannelPost
+	case upd.EditedChannelPost != nil:
+		return upd.EditedChannelPost
+	}
+	return nil
+}
+
+// IsUpdate creates filter that accepts updates of any of the types.
+func IsUpdate(types ...UpdateType) Filter {
+	return func(upd *Update) bool {
+		typ := upd.Type()
+		for _, t := range types {
+			if typ == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsChat creates filter that accepts updates from chat of any of the types.
+func IsChat(types ...ChatType) Filter {
+	return func(upd *Update) bool {
+		chat := upd.Chat()
+		if chat == nil {
+			return false
+		}
+		for _, t := range types {
+			if chat.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsPrivate accepts updates from private chat.
+func IsPrivate(upd *Update) bool {
+	chat := upd.Chat()
+	return chat != nil && chat.Type == PrivateChat
+}
+
+// IsGroup accepts updates from group or supergroup chat.
+func IsGroup(upd *Update) bool {
+	chat := upd.Chat()
+	return chat != nil && (chat.Type == GroupChat || chat.Type == SupergroupChat)
+}
+
+// FromUsers creates filter that accepts updates sent by any of the users.
+func FromUsers(ids ...int64) Filter {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return func(upd *Update) bool {
+		from := upd.From()
+		return from != nil && set[from.ID]
+	}
+}
+
+// TextMatches creates filter that accepts messages with text or caption
+// matching re.
+func TextMatches(re *regexp.Regexp) Filter {
+	return func(upd *Update) bool {
+		msg := message(upd)
+		if msg == nil {
+			return false
+		}
+		if len(msg.Text) > 0 {
+			return re.MatchString(msg.Text)
+		}
+		return re.MatchString(msg.Caption)
+	}
+}
+
+// HasEntity creates filter that accepts messages containing an entity of any
+// of the types in its text or caption.
+func HasEntity(types ...MessageEntityType) Filter {
+	return func(upd *Update) bool {
+		msg := message(upd)
+		if msg == nil {
+			return false
+		}
+		for _, entities := range [][]*MessageEntity{msg.Entities, msg.CaptionEntities} {
+			for _, e := range entities {
+				for _, t := range types {
+					if e.Type == t {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+}
+
+// HasLocation accepts messages containing a location.
+func HasLocation(upd *Update) bool {
+	msg := message(upd)
+	return msg != nil && msg.Location != nil
+}
+
+// HasContact accepts messages containing a contact.
+func HasContact(upd *Update) bool {
+	msg := message(upd)
+	return msg != nil && msg.Contact != nil
+}
+
+// IsReply accepts messages replying to another message.
+func IsReply(upd *Update) bool {
+	msg := message(upd)
+	return msg != nil && msg.ReplyToMessage != nil
+}
+
+// ReplyTo creates filter that accepts messages replying to a message sent by
+// the user. Use the bot identifier to accept replies to the bot messages.
+func ReplyTo(userID int64) Filter {
+	return func(upd *Update) bool {
+		msg := message(upd)
+		return msg != nil && msg.ReplyToMessage != nil &&
+			msg.ReplyToMessage.From != nil && msg.ReplyToMessage.From.ID == userID
+	}
+}
@@ -0,0 +1,47 @@
+package telebot
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Handler responds to an incoming update. The returned error is reported to
+// the bot ErrorHandler and does not stop the update delivery.
+type Handler interface {
+	ServeUpdate(ctx context.Context, upd *Update) error
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as update
+// handlers.
+type HandlerFunc func(ctx context.Context, upd *Update) error
+
+// ServeUpdate implements the Handler interface.
+func (f HandlerFunc) ServeUpdate(ctx context.Context, upd *Update) error {
+	return f(ctx, upd)
+}
+
+// reportError passes err to fn or logs it with the standard logger if fn is
+// nil.
+func reportError(fn func(err error), err error) {
+	if fn != nil {
+		fn(err)
+		return
+	}
+	log.Printf("telebot: %v", err)
+}
+
+// detachedContext keeps the values of its parent but is never cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// detach creates context carrying the values of ctx without its cancellation,
+// for handlers running after the request that delivered the update returns.
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
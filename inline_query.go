@@ -452,7 +452,7 @@ func (r *InlineQueryResultContact) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&inlineQueryResultContact{r.Type(), (*inlineQueryResultContactBase)(r)})
 }
 
-// MessageContentType represents the input message content type.
+// MessageContentType represents the message content type.
 type MessageContentType string
 
 const (
@@ -467,6 +467,30 @@ const (
 
 	// ContactMessage represents the contact message content type.
 	ContactMessage MessageContentType = "contact"
+
+	// PhotoMessage represents the photo message content type.
+	PhotoMessage MessageContentType = "photo"
+
+	// AudioMessage represents the audio message content type.
+	AudioMessage MessageContentType = "audio"
+
+	// DocumentMessage represents the document message content type.
+	DocumentMessage MessageContentType = "document"
+
+	// VideoMessage represents the video message content type.
+	VideoMessage MessageContentType = "video"
+
+	// AnimationMessage represents the animation message content type.
+	AnimationMessage MessageContentType = "animation"
+
+	// VoiceMessage represents the voice message content type.
+	VoiceMessage MessageContentType = "voice"
+
+	// VideoNoteMessage represents the video note message content type.
+	VideoNoteMessage MessageContentType = "video_note"
+
+	// StickerMessage represents the sticker message content type.
+	StickerMessage MessageContentType = "sticker"
 )
 
 // InputMessageContent represents the content of a message to be sent as a
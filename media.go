@@ -0,0 +1,317 @@
+package telebot
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// SendPhoto send photos.
+type SendPhoto struct {
+	ChatID              int64       `json:"chat_id"`
+	Photo               *InputFile  `json:"photo"`
+	Caption             string      `json:"caption,omitempty"`
+	ParseMode           ParseMode   `json:"parse_mode,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64       `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+// Type implements the SendRequest interface.
+func (m *SendPhoto) Type() SendRequestType {
+	return SendPhotoRequest
+}
+
+// SendAudio send audio files to be displayed in the music player. The audio
+// must be in the MP3 or M4A format.
+type SendAudio struct {
+	ChatID              int64       `json:"chat_id"`
+	Audio               *InputFile  `json:"audio"`
+	Caption             string      `json:"caption,omitempty"`
+	ParseMode           ParseMode   `json:"parse_mode,omitempty"`
+	Duration            int         `json:"duration,omitempty"`
+	Performer           string      `json:"performer,omitempty"`
+	Title               string      `json:"title,omitempty"`
+	Thumb               *InputFile  `json:"thumb,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64       `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+// Type implements the SendRequest interface.
+func (m *SendAudio) Type() SendRequestType {
+	return SendAudioRequest
+}
+
+// SendDocument send general files.
+type SendDocument struct {
+	ChatID              int64       `json:"chat_id"`
+	Document            *InputFile  `json:"document"`
+	Thumb               *InputFile  `json:"thumb,omitempty"`
+	Caption             string      `json:"caption,omitempty"`
+	ParseMode           ParseMode   `json:"parse_mode,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64       `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+// Type implements the SendRequest interface.
+func (m *SendDocument) Type() SendRequestType {
+	return SendDocumentRequest
+}
+
+// SendVideo send video files. Telegram clients support MPEG4 videos.
+type SendVideo struct {
+	ChatID              int64       `json:"chat_id"`
+	Video               *InputFile  `json:"video"`
+	Duration            int         `json:"duration,omitempty"`
+	Width               int         `json:"width,omitempty"`
+	Height              int         `json:"height,omitempty"`
+	Thumb               *InputFile  `json:"thumb,omitempty"`
+	Caption             string      `json:"caption,omitempty"`
+	ParseMode           ParseMode   `json:"parse_mode,omitempty"`
+	SupportsStreaming   bool        `json:"supports_streaming,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64       `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+// Type implements the SendRequest interface.
+func (m *SendVideo) Type() SendRequestType {
+	return SendVideoRequest
+}
+
+// SendAnimation send animation files (GIF or H.264/MPEG-4 AVC video without
+// sound).
+type SendAnimation struct {
+	ChatID              int64       `json:"chat_id"`
+	Animation           *InputFile  `json:"animation"`
+	Duration            int         `json:"duration,omitempty"`
+	Width               int         `json:"width,omitempty"`
+	Height              int         `json:"height,omitempty"`
+	Thumb               *InputFile  `json:"thumb,omitempty"`
+	Caption             string      `json:"caption,omitempty"`
+	ParseMode           ParseMode   `json:"parse_mode,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64       `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+// Type implements the SendRequest interface.
+func (m *SendAnimation) Type() SendRequestType {
+	return SendAnimationRequest
+}
+
+// SendVoice send audio files to be displayed as a playable voice message. The
+// audio must be in an OGG file encoded with OPUS.
+type SendVoice struct {
+	ChatID              int64       `json:"chat_id"`
+	Voice               *InputFile  `json:"voice"`
+	Caption             string      `json:"caption,omitempty"`
+	ParseMode           ParseMode   `json:"parse_mode,omitempty"`
+	Duration            int         `json:"duration,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64       `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+// Type implements the SendRequest interface.
+func (m *SendVoice) Type() SendRequestType {
+	return SendVoiceRequest
+}
+
+// SendVideoNote send rounded square MPEG4 videos of up to 1 minute long. Video
+// notes do not support captions.
+type SendVideoNote struct {
+	ChatID              int64       `json:"chat_id"`
+	VideoNote           *InputFile  `json:"video_note"`
+	Duration            int         `json:"duration,omitempty"`
+	Length              int         `json:"length,omitempty"`
+	Thumb               *InputFile  `json:"thumb,omitempty"`
+	DisableNotification bool        `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64       `json:"reply_to_message_id,omitempty"`
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`
+}
+
+// Type implements the SendRequest interface.
+func (m *SendVideoNote) Type() SendRequestType {
+	return SendVideoNoteRequest
+}
+
+// InputMediaType represents the input media type.
+type InputMediaType string
+
+const (
+	// PhotoMedia represents the photo input media type.
+	PhotoMedia InputMediaType = "photo"
+
+	// VideoMedia represents the video input media type.
+	VideoMedia InputMediaType = "video"
+
+	// AudioMedia represents the audio input media type.
+	AudioMedia InputMediaType = "audio"
+
+	// DocumentMedia represents the document input media type.
+	DocumentMedia InputMediaType = "document"
+)
+
+// InputMedia represents the content of a media message to be sent.
+type InputMedia interface {
+	Type() InputMediaType
+}
+
+// attachments is implemented by values carrying input files that are not
+// direct request fields, so they can be uploaded as attach:// references.
+type attachments interface {
+	inputFiles() []*InputFile
+}
+
+// InputMediaPhoto represents a photo to be sent.
+type InputMediaPhoto struct {
+	Media     *InputFile `json:"media"`
+	Caption   string     `json:"caption,omitempty"`
+	ParseMode ParseMode  `json:"parse_mode,omitempty"`
+}
+
+// Type implements InputMedia interface.
+func (m *InputMediaPhoto) Type() InputMediaType {
+	return PhotoMedia
+}
+
+func (m *InputMediaPhoto) inputFiles() []*InputFile {
+	return []*InputFile{m.Media}
+}
+
+type inputMediaPhotoBase InputMediaPhoto
+
+type inputMediaPhoto struct {
+	Type InputMediaType `json:"type"`
+	*inputMediaPhotoBase
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (m *InputMediaPhoto) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&inputMediaPhoto{m.Type(), (*inputMediaPhotoBase)(m)})
+}
+
+// InputMediaVideo represents a video to be sent.
+type InputMediaVideo struct {
+	Media             *InputFile `json:"media"`
+	Thumb             *InputFile `json:"thumb,omitempty"`
+	Caption           string     `json:"caption,omitempty"`
+	ParseMode         ParseMode  `json:"parse_mode,omitempty"`
+	Width             int        `json:"width,omitempty"`
+	Height            int        `json:"height,omitempty"`
+	Duration          int        `json:"duration,omitempty"`
+	SupportsStreaming bool       `json:"supports_streaming,omitempty"`
+}
+
+// Type implements InputMedia interface.
+func (m *InputMediaVideo) Type() InputMediaType {
+	return VideoMedia
+}
+
+func (m *InputMediaVideo) inputFiles() []*InputFile {
+	return []*InputFile{m.Media, m.Thumb}
+}
+
+type inputMediaVideoBase InputMediaVideo
+
+type inputMediaVideo struct {
+	Type InputMediaType `json:"type"`
+	*inputMediaVideoBase
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (m *InputMediaVideo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&inputMediaVideo{m.Type(), (*inputMediaVideoBase)(m)})
+}
+
+// InputMediaAudio represents an audio file to be treated as music to be sent.
+type InputMediaAudio struct {
+	Media     *InputFile `json:"media"`
+	Thumb     *InputFile `json:"thumb,omitempty"`
+	Caption   string     `json:"caption,omitempty"`
+	ParseMode ParseMode  `json:"parse_mode,omitempty"`
+	Duration  int        `json:"duration,omitempty"`
+	Performer string     `json:"performer,omitempty"`
+	Title     string     `json:"title,omitempty"`
+}
+
+// Type implements InputMedia interface.
+func (m *InputMediaAudio) Type() InputMediaType {
+	return AudioMedia
+}
+
+func (m *InputMediaAudio) inputFiles() []*InputFile {
+	return []*InputFile{m.Media, m.Thumb}
+}
+
+type inputMediaAudioBase InputMediaAudio
+
+type inputMediaAudio struct {
+	Type InputMediaType `json:"type"`
+	*inputMediaAudioBase
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (m *InputMediaAudio) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&inputMediaAudio{m.Type(), (*inputMediaAudioBase)(m)})
+}
+
+// InputMediaDocument represents a general file to be sent.
+type InputMediaDocument struct {
+	Media     *InputFile `json:"media"`
+	Thumb     *InputFile `json:"thumb,omitempty"`
+	Caption   string     `json:"caption,omitempty"`
+	ParseMode ParseMode  `json:"parse_mode,omitempty"`
+}
+
+// Type implements InputMedia interface.
+func (m *InputMediaDocument) Type() InputMediaType {
+	return DocumentMedia
+}
+
+func (m *InputMediaDocument) inputFiles() []*InputFile {
+	return []*InputFile{m.Media, m.Thumb}
+}
+
+type inputMediaDocumentBase InputMediaDocument
+
+type inputMediaDocument struct {
+	Type InputMediaType `json:"type"`
+	*inputMediaDocumentBase
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (m *InputMediaDocument) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&inputMediaDocument{m.Type(), (*inputMediaDocumentBase)(m)})
+}
+
+// SendMediaGroup sets parameter for SendMediaGroup method. Media must contain
+// 2-10 photos and videos, or only audio files, or only documents.
+type SendMediaGroup struct {
+	ChatID              int64        `json:"chat_id"`
+	Media               []InputMedia `json:"media"`
+	DisableNotification bool         `json:"disable_notification,omitempty"`
+	ReplyToMessageID    int64        `json:"reply_to_message_id,omitempty"`
+}
+
+// SendMediaGroup send a group of photos, videos, documents or audios as an
+// album.
+func (b *Bot) SendMediaGroup(req *SendMediaGroup) ([]*Message, error) {
+	return b.SendMediaGroupContext(context.Background(), req)
+}
+
+// SendMediaGroupContext is like SendMediaGroup but with cancellation context.
+// If rate limiter is set, it waits for the chat and global limit before
+// sending the request.
+func (b *Bot) SendMediaGroupContext(ctx context.Context, req *SendMediaGroup) ([]*Message, error) {
+	if b.limiter != nil {
+		if err := b.limiter.Wait(ctx, req.ChatID); err != nil {
+			return nil, err
+		}
+	}
+	var msgs []*Message
+	err := b.caller.CallContext(ctx, "sendMediaGroup", req, &msgs)
+	return msgs, err
+}
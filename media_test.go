@@ -0,0 +1,109 @@
+package telebot_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adzil/telebot"
+	"github.com/adzil/telebot/telebottest"
+)
+
+func TestSendPhotoMultipart(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	srv.Handle("sendPhoto", func(call *telebottest.Call) (interface{}, error) {
+		return &telebot.Message{ID: 1, Chat: &telebot.Chat{ID: call.Int64("chat_id")}}, nil
+	})
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := bot.Send(&telebot.SendPhoto{
+		ChatID:  5,
+		Photo:   telebot.NewInputFileReader("photo.jpg", strings.NewReader("JPEG")),
+		Caption: "caption",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Chat.ID != 5 {
+		t.Fatalf("message chat = %d, want 5", msg.Chat.ID)
+	}
+	calls := srv.CallsTo("sendPhoto")
+	if len(calls) != 1 {
+		t.Fatalf("got %d sendPhoto calls, want 1", len(calls))
+	}
+	call := calls[0]
+	if call.Int64("chat_id") != 5 || call.Params["caption"] != "caption" {
+		t.Fatalf("unexpected params %v", call.Params)
+	}
+	// Uploaded file is sent as a form file instead of a parameter
+	if _, ok := call.Params["photo"]; ok {
+		t.Fatalf("photo sent as parameter %q", call.Params["photo"])
+	}
+	f := call.Files["photo"]
+	if f == nil || f.Name != "photo.jpg" || string(f.Data) != "JPEG" {
+		t.Fatalf("unexpected photo file %+v", f)
+	}
+}
+
+func TestSendPhotoFileID(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	srv.Handle("sendPhoto", func(call *telebottest.Call) (interface{}, error) {
+		return &telebot.Message{ID: 1}, nil
+	})
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Send(&telebot.SendPhoto{ChatID: 5, Photo: telebot.NewInputFileID("file-id")}); err != nil {
+		t.Fatal(err)
+	}
+	call := srv.CallsTo("sendPhoto")[0]
+	if call.Params["photo"] != "file-id" || len(call.Files) != 0 {
+		t.Fatalf("unexpected call %+v", call)
+	}
+}
+
+func TestSendMediaGroupAttachments(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	srv.Handle("sendMediaGroup", func(call *telebottest.Call) (interface{}, error) {
+		return []*telebot.Message{{ID: 1}, {ID: 2}}, nil
+	})
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := bot.SendMediaGroup(&telebot.SendMediaGroup{
+		ChatID: 5,
+		Media: []telebot.InputMedia{
+			&telebot.InputMediaPhoto{
+				Media:   telebot.NewInputFileReader("a.jpg", strings.NewReader("A")),
+				Caption: "x",
+			},
+			&telebot.InputMediaVideo{
+				Media: telebot.NewInputFileID("vid"),
+				Thumb: telebot.NewInputFileReader("t.jpg", strings.NewReader("T")),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	call := srv.CallsTo("sendMediaGroup")[0]
+	// Uploaded files are referenced with attach:// in the media parameter
+	want := `[{"type":"photo","media":"attach://file0","caption":"x"},{"type":"video","media":"vid","thumb":"attach://file1"}]`
+	if call.Params["media"] != want {
+		t.Fatalf("media = %s, want %s", call.Params["media"], want)
+	}
+	for name, data := range map[string]string{"file0": "A", "file1": "T"} {
+		if f := call.Files[name]; f == nil || string(f.Data) != data {
+			t.Fatalf("unexpected %s file %+v", name, f)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package telebot
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+// Middleware wraps a handler to run additional logic around it, such as
+// logging, panic recovery or authorization.
+type Middleware func(h Handler) Handler
+
+// Chain wraps h with middlewares. The first middleware is the outermost one,
+// so it runs first.
+func Chain(h Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// logf prints log message with logger or the standard logger if it is nil.
+func logf(logger *log.Logger, format string, v ...interface{}) {
+	if logger == nil {
+		log.Printf(format, v...)
+		return
+	}
+	logger.Printf(format, v...)
+}
+
+// Recover creates middleware that recovers from panic in the handler, logs
+// the update with the stack trace and keeps the bot alive. If logger is nil,
+// the standard logger is used.
+func Recover(logger *log.Logger) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, upd *Update) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					buf, _ := json.Marshal(upd)
+					logf(logger, "telebot: panic serving update_id=%d: %v\nupdate=%s\n%s",
+						upd.ID, v, buf, debug.Stack())
+					err = nil
+				}
+			}()
+			return h.ServeUpdate(ctx, upd)
+		})
+	}
+}
+
+// Logger creates middleware that logs every update with its type, handling
+// duration and error in key=value format. If logger is nil, the standard
+// logger is used.
+func Logger(logger *log.Logger) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, upd *Update) error {
+			start := time.Now()
+			err := h.ServeUpdate(ctx, upd)
+			if err != nil {
+				logf(logger, "telebot: update_id=%d type=%s duration=%s error=%q",
+					upd.ID, upd.Type(), time.Since(start), err)
+			} else {
+				logf(logger, "telebot: update_id=%d type=%s duration=%s",
+					upd.ID, upd.Type(), time.Since(start))
+			}
+			return err
+		})
+	}
+}
+
+// Guard creates middleware that only passes updates accepted by allow to the
+// handler and silently drops the rest. Use it to gate handlers behind
+// authorization checks.
+func Guard(allow func(upd *Update) bool) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, upd *Update) error {
+			if !allow(upd) {
+				return nil
+			}
+			return h.ServeUpdate(ctx, upd)
+		})
+	}
+}
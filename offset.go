@@ -0,0 +1,207 @@
+package telebot
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OffsetStore persists the offset of the next update to be processed, so
+// polling can resume exactly where processing stopped after restart. Commit is
+// called with the identifier of the last acknowledged update plus one.
+type OffsetStore interface {
+	Load() (int64, error)
+	Commit(offset int64) error
+}
+
+// MemoryOffsetStore implements OffsetStore in memory. It does not survive
+// restarts but can be used to share the offset between polling sessions.
+type MemoryOffsetStore struct {
+	mu     sync.Mutex
+	offset int64
+}
+
+// Load implements the OffsetStore interface.
+func (s *MemoryOffsetStore) Load() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.offset, nil
+}
+
+// Commit implements the OffsetStore interface.
+func (s *MemoryOffsetStore) Commit(offset int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset = offset
+	return nil
+}
+
+// FileOffsetStore implements OffsetStore with a local file. The file is
+// replaced atomically on every commit.
+type FileOffsetStore struct {
+	Path string
+}
+
+// NewFileOffsetStore creates new file offset store at path.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{Path: path}
+}
+
+// Load implements the OffsetStore interface. It returns zero offset if the
+// file does not exist yet.
+func (s *FileOffsetStore) Load() (int64, error) {
+	buf, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(buf)), 10, 64)
+}
+
+// Commit implements the OffsetStore interface.
+func (s *FileOffsetStore) Commit(offset int64) error {
+	return writeFileAtomic(s.Path, func(w io.Writer) error {
+		_, err := io.WriteString(w, strconv.FormatInt(offset, 10)+"\n")
+		return err
+	})
+}
+
+// writeFileAtomic writes into a unique temporary file next to path, flushes it
+// to disk and renames it over path, so concurrent writers never interleave and
+// a crash never leaves a partial file. The temporary file is removed on
+// failure.
+func writeFileAtomic(path string, write func(w io.Writer) error) (err error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	if err = write(f); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// ackKey is the context key for deferred acknowledgement.
+type ackKey struct{}
+
+// deferredAck holds the acknowledgement of an update being handled.
+type deferredAck struct {
+	deferred bool
+	once     sync.Once
+	fn       func()
+}
+
+// ack acknowledges the update once.
+func (d *deferredAck) ack() {
+	d.once.Do(d.fn)
+}
+
+// DeferAck detaches the acknowledgement of the update being handled from the
+// handler return. By default, Poll acknowledges an update once the handler
+// returns. Asynchronous handlers must call DeferAck before returning and call
+// the returned function once the update is processed. It returns a no-op
+// function if ctx does not come from Poll.
+func DeferAck(ctx context.Context) func() {
+	d, ok := ctx.Value(ackKey{}).(*deferredAck)
+	if !ok {
+		return func() {}
+	}
+	d.deferred = true
+	return d.ack
+}
+
+// ackTracker tracks acknowledged updates and advances the offset only when
+// all prior updates are acknowledged.
+type ackTracker struct {
+	mu      sync.Mutex
+	bot     *Bot
+	offset  int64
+	pending []int64
+	acked   map[int64]bool
+	notify  chan struct{}
+}
+
+// newAckTracker creates new tracker starting at offset.
+func newAckTracker(bot *Bot, offset int64) *ackTracker {
+	return &ackTracker{
+		bot:    bot,
+		offset: offset,
+		acked:  make(map[int64]bool),
+		notify: make(chan struct{}),
+	}
+}
+
+// add registers update id as in-flight and returns its acknowledgement.
+func (t *ackTracker) add(id int64) *deferredAck {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, id)
+	return &deferredAck{fn: func() { t.ack(id) }}
+}
+
+// ack marks update id as acknowledged and commits the offset if advanced.
+func (t *ackTracker) ack(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.acked[id] = true
+	// Pop contiguous acknowledged updates
+	advanced := false
+	for len(t.pending) > 0 && t.acked[t.pending[0]] {
+		delete(t.acked, t.pending[0])
+		t.offset = t.pending[0] + 1
+		t.pending = t.pending[1:]
+		advanced = true
+	}
+	if !advanced {
+		return
+	}
+	// Wake up waiting poller and commit the offset
+	close(t.notify)
+	t.notify = make(chan struct{})
+	if t.bot.Offsets != nil {
+		if err := t.bot.Offsets.Commit(t.offset); err != nil {
+			reportError(t.bot.ErrorHandler, err)
+		}
+	}
+}
+
+// current returns the committed offset.
+func (t *ackTracker) current() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.offset
+}
+
+// wait blocks until the committed offset advances beyond offset.
+func (t *ackTracker) wait(ctx context.Context, offset int64) error {
+	t.mu.Lock()
+	if t.offset > offset {
+		t.mu.Unlock()
+		return nil
+	}
+	notify := t.notify
+	t.mu.Unlock()
+	select {
+	case <-notify:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
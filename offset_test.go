@@ -0,0 +1,105 @@
+package telebot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adzil/telebot"
+	"github.com/adzil/telebot/telebottest"
+)
+
+func TestPollDeferredAck(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &telebot.MemoryOffsetStore{}
+	bot.Offsets = store
+	chat := &telebot.Chat{ID: 1, Type: telebot.PrivateChat}
+	for _, text := range []string{"a", "b", "c"} {
+		if _, err := srv.PushMessage(chat, &telebot.User{ID: 1}, text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Defer the acknowledgement of every update
+	acks := make(chan func(), 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- bot.Poll(ctx, &telebot.GetUpdates{Timeout: 1}, telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+			acks <- telebot.DeferAck(ctx)
+			return nil
+		}))
+	}()
+	ack1, ack2 := <-acks, <-acks
+	<-acks
+	expectOffset(t, store, 0)
+	// Out of order acknowledgement must not advance the offset
+	ack2()
+	expectOffset(t, store, 0)
+	ack1()
+	expectOffset(t, store, 3)
+	// Restart before the last update is acknowledged
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Poll returned %v, want %v", err, context.Canceled)
+	}
+	// Polling resumes from the first unacknowledged update
+	ids := make(chan int64, 3)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		done <- bot.Poll(ctx, &telebot.GetUpdates{Timeout: 1}, telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+			ids <- upd.ID
+			return nil
+		}))
+	}()
+	select {
+	case id := <-ids:
+		if id != 3 {
+			t.Fatalf("resumed at update %d, want 3", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("update was not redelivered")
+	}
+	waitOffset(t, store, 4)
+	cancel()
+	<-done
+	select {
+	case id := <-ids:
+		t.Fatalf("unexpected update %d", id)
+	default:
+	}
+}
+
+func expectOffset(t *testing.T, store telebot.OffsetStore, want int64) {
+	t.Helper()
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("offset = %d, want %d", got, want)
+	}
+}
+
+func waitOffset(t *testing.T, store telebot.OffsetStore, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("offset = %d, want %d", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,92 @@
+package telebot
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// options holds the configuration for NewBot and NewCaller.
+type options struct {
+	endpoint     string
+	fileEndpoint string
+	client       *http.Client
+	pollClient   *http.Client
+	skipGetMe    bool
+	localMode    bool
+}
+
+// Option configures Bot and Caller created by NewBot and NewCaller.
+type Option func(*options)
+
+// WithHTTPClient sets HTTP client used for regular method calls. Use this to
+// inject proxy, custom TLS configuration or test transport.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithPollClient sets HTTP client used for long-polling method calls. Its
+// timeout must be longer than the polling timeout.
+func WithPollClient(client *http.Client) Option {
+	return func(o *options) {
+		o.pollClient = client
+	}
+}
+
+// WithEndpoint sets Telegram Bot API endpoint URL that will be prefixed to the
+// token, such as a self-hosted Bot API server or a test server. It defaults to
+// EndpointURL.
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithFileEndpoint sets the endpoint URL used to download files that will be
+// prefixed to the token. It defaults to the endpoint with the trailing "bot"
+// replaced by "file/bot".
+func WithFileEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.fileEndpoint = endpoint
+	}
+}
+
+// WithLocalMode tells that the endpoint is a self-hosted Bot API server
+// running in local mode on the same machine, so getFile returns absolute
+// paths that are read directly from the local filesystem.
+func WithLocalMode() Option {
+	return func(o *options) {
+		o.localMode = true
+	}
+}
+
+// WithoutGetMe skips the getMe call on NewBot. Bot information will be looked
+// up lazily with the Me method.
+func WithoutGetMe() Option {
+	return func(o *options) {
+		o.skipGetMe = true
+	}
+}
+
+// newOptions creates options with default values and applies opts.
+func newOptions(endpoint string, opts []Option) *options {
+	o := &options{
+		endpoint: endpoint,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		pollClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	// Derive file endpoint from the method endpoint
+	if len(o.fileEndpoint) == 0 {
+		o.fileEndpoint = strings.TrimSuffix(o.endpoint, "bot") + "file/bot"
+	}
+	return o
+}
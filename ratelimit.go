@@ -0,0 +1,208 @@
+package telebot
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Rate represents the number of requests allowed within a period.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+var (
+	// DefaultGlobalRate used to limit requests across all chats.
+	DefaultGlobalRate = Rate{Limit: 30, Period: time.Second}
+
+	// DefaultPrivateRate used to limit requests to a private chat.
+	DefaultPrivateRate = Rate{Limit: 1, Period: time.Second}
+
+	// DefaultGroupRate used to limit requests to a group, supergroup or
+	// channel.
+	DefaultGroupRate = Rate{Limit: 20, Period: time.Minute}
+)
+
+// sweepPeriod used to remove idle chat buckets.
+const sweepPeriod = time.Minute
+
+// bucket implements token bucket algorithm. The bucket capacity equals the
+// rate limit so it allows burst up to the limit.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds tokens to bucket according to elapsed time since last refill.
+func (b *bucket) refill(now time.Time, r Rate) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) * float64(r.Limit) / float64(r.Period)
+		if b.tokens > float64(r.Limit) {
+			b.tokens = float64(r.Limit)
+		}
+	}
+	b.last = now
+}
+
+// wait returns the waiting period until a token is available.
+func (b *bucket) wait(now time.Time, r Rate) time.Duration {
+	if r.Limit <= 0 || r.Period <= 0 {
+		return 0
+	}
+	b.refill(now, r)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(r.Period) / float64(r.Limit))
+}
+
+// full reports whether bucket has no pending usage at now.
+func (b *bucket) full(now time.Time, r Rate) bool {
+	b.refill(now, r)
+	return b.tokens >= float64(r.Limit)
+}
+
+// RateLimiter throttles outgoing requests to match Telegram limits with token
+// buckets for every chat and a global bucket across all chats. Private chats
+// are identified by positive chat identifier and use Private rate, while
+// groups and channels use Group rate. Do not modify the rates after the first
+// call to Wait.
+type RateLimiter struct {
+	Global  Rate
+	Private Rate
+	Group   Rate
+	mu      sync.Mutex
+	global  *bucket
+	chats   map[int64]*bucket
+	waiting map[int64]int
+	queued  int
+	swept   time.Time
+}
+
+// NewRateLimiter creates new rate limiter with default rates.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		Global:  DefaultGlobalRate,
+		Private: DefaultPrivateRate,
+		Group:   DefaultGroupRate,
+	}
+}
+
+// chatRate returns the rate for chatID.
+func (l *RateLimiter) chatRate(chatID int64) Rate {
+	if chatID < 0 {
+		return l.Group
+	}
+	return l.Private
+}
+
+// init lazily creates the buckets. Must be called with lock held.
+func (l *RateLimiter) init(now time.Time) {
+	if l.chats == nil {
+		l.global = &bucket{tokens: float64(l.Global.Limit), last: now}
+		l.chats = make(map[int64]*bucket)
+		l.waiting = make(map[int64]int)
+		l.swept = now
+	}
+}
+
+// sweep removes idle chat buckets. Must be called with lock held.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < sweepPeriod {
+		return
+	}
+	for chatID, b := range l.chats {
+		if l.waiting[chatID] == 0 && b.full(now, l.chatRate(chatID)) {
+			delete(l.chats, chatID)
+		}
+	}
+	l.swept = now
+}
+
+// Wait blocks until a request to chatID is allowed or ctx is cancelled. Use
+// zero chatID for request that is not bound to a chat, so it only takes the
+// global limit into account.
+func (l *RateLimiter) Wait(ctx context.Context, chatID int64) error {
+	// Register into the queue
+	l.mu.Lock()
+	l.init(time.Now())
+	l.queued++
+	l.waiting[chatID]++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		if l.waiting[chatID]--; l.waiting[chatID] <= 0 {
+			delete(l.waiting, chatID)
+		}
+		l.mu.Unlock()
+	}()
+	// Loop until both global and chat bucket have token
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.sweep(now)
+		d := l.global.wait(now, l.Global)
+		var chat *bucket
+		if chatID != 0 {
+			chat = l.chats[chatID]
+			if chat == nil {
+				rate := l.chatRate(chatID)
+				chat = &bucket{tokens: float64(rate.Limit), last: now}
+				l.chats[chatID] = chat
+			}
+			if cd := chat.wait(now, l.chatRate(chatID)); cd > d {
+				d = cd
+			}
+		}
+		// Take tokens if available
+		if d == 0 {
+			l.global.tokens--
+			if chat != nil {
+				chat.tokens--
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+		// Sleep until the token should be available
+		if err := sleepContext(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// QueueDepth returns the number of requests waiting across all chats.
+func (l *RateLimiter) QueueDepth() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.queued
+}
+
+// ChatQueueDepth returns the number of requests waiting for chatID.
+func (l *RateLimiter) ChatQueueDepth(chatID int64) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiting[chatID]
+}
+
+// chatIDOf gets the value of ChatID field from request or zero if the request
+// does not have one.
+func chatIDOf(request interface{}) int64 {
+	v := reflect.ValueOf(request)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+	if f := v.FieldByName("ChatID"); f.IsValid() && f.Kind() == reflect.Int64 {
+		return f.Int()
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package telebot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitElapsed calls Wait for chatID and returns the time spent waiting.
+func waitElapsed(t *testing.T, l *RateLimiter, chatID int64) time.Duration {
+	t.Helper()
+	start := time.Now()
+	if err := l.Wait(context.Background(), chatID); err != nil {
+		t.Fatal(err)
+	}
+	return time.Since(start)
+}
+
+func TestRateLimiterChatLimit(t *testing.T) {
+	l := &RateLimiter{
+		Global:  Rate{Limit: 100, Period: time.Second},
+		Private: Rate{Limit: 2, Period: 100 * time.Millisecond},
+		Group:   Rate{Limit: 1, Period: 300 * time.Millisecond},
+	}
+	// Burst up to the limit is allowed
+	for i := 0; i < 2; i++ {
+		if d := waitElapsed(t, l, 1); d > 20*time.Millisecond {
+			t.Fatalf("request %d waited %v, want no wait", i, d)
+		}
+	}
+	// Other chats are not affected by the exhausted chat
+	if d := waitElapsed(t, l, 2); d > 20*time.Millisecond {
+		t.Fatalf("other private chat waited %v, want no wait", d)
+	}
+	if d := waitElapsed(t, l, -1); d > 20*time.Millisecond {
+		t.Fatalf("group chat waited %v, want no wait", d)
+	}
+	if d := waitElapsed(t, l, 1); d < 40*time.Millisecond {
+		t.Fatalf("exhausted private chat waited %v, want at least 40ms", d)
+	}
+	if d := waitElapsed(t, l, -1); d < 150*time.Millisecond {
+		t.Fatalf("exhausted group chat waited %v, want at least 150ms", d)
+	}
+}
+
+func TestRateLimiterGlobalLimit(t *testing.T) {
+	l := &RateLimiter{
+		Global:  Rate{Limit: 2, Period: 100 * time.Millisecond},
+		Private: Rate{Limit: 10, Period: time.Second},
+	}
+	waitElapsed(t, l, 1)
+	waitElapsed(t, l, 2)
+	if d := waitElapsed(t, l, 3); d < 40*time.Millisecond {
+		t.Fatalf("request waited %v, want at least 40ms", d)
+	}
+	// Requests without chat only take the global limit
+	if d := waitElapsed(t, l, 0); d < 40*time.Millisecond {
+		t.Fatalf("request without chat waited %v, want at least 40ms", d)
+	}
+}
+
+func TestRateLimiterQueueDepth(t *testing.T) {
+	l := &RateLimiter{
+		Global:  Rate{Limit: 100, Period: time.Second},
+		Private: Rate{Limit: 1, Period: 50 * time.Millisecond},
+	}
+	waitElapsed(t, l, 1)
+	// Queue requests behind the exhausted chat
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(context.Background(), 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- l.Wait(ctx, 1)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for l.QueueDepth() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth = %d, want 4", l.QueueDepth())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := l.ChatQueueDepth(2); n != 0 {
+		t.Fatalf("other chat queue depth = %d, want 0", n)
+	}
+	// Cancelled request leaves the queue
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Fatalf("Wait returned %v, want %v", err, context.Canceled)
+	}
+	wg.Wait()
+	if n := l.QueueDepth(); n != 0 {
+		t.Fatalf("queue depth = %d, want 0", n)
+	}
+	if n := l.ChatQueueDepth(1); n != 0 {
+		t.Fatalf("chat queue depth = %d, want 0", n)
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l := &RateLimiter{
+		Global:  Rate{Limit: 100, Period: time.Second},
+		Private: Rate{Limit: 1, Period: time.Second},
+	}
+	waitElapsed(t, l, 1)
+	waitElapsed(t, l, 2)
+	// Pretend the buckets have been idle for a sweep period
+	l.mu.Lock()
+	past := time.Now().Add(-2 * sweepPeriod)
+	l.swept = past
+	l.chats[1].last = past
+	l.mu.Unlock()
+	waitElapsed(t, l, 0)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.chats[1]; ok {
+		t.Fatal("idle chat bucket was not removed")
+	}
+	if _, ok := l.chats[2]; !ok {
+		t.Fatal("recently used chat bucket was removed")
+	}
+}
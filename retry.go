@@ -0,0 +1,152 @@
+package telebot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries used to limit the number of retries of a request.
+	DefaultMaxRetries = 3
+
+	// DefaultMinBackoff used as the initial exponential backoff period.
+	DefaultMinBackoff = 500 * time.Millisecond
+
+	// DefaultMaxBackoff used to cap the exponential backoff period.
+	DefaultMaxBackoff = 30 * time.Second
+
+	// DefaultBackoffJitter used to randomize the backoff period.
+	DefaultBackoffJitter = 0.2
+)
+
+// Backoff implements capped exponential backoff period. Jitter randomly
+// shortens the period by up to the given fraction, so multiple clients do not
+// retry at the same time.
+type Backoff struct {
+	Min    time.Duration
+	Max    time.Duration
+	Jitter float64
+}
+
+// Duration returns the backoff period for n-th attempt starting from zero.
+func (b *Backoff) Duration(attempt int) time.Duration {
+	// Fill unset value with default
+	min, max := b.Min, b.Max
+	if min <= 0 {
+		min = DefaultMinBackoff
+	}
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	if max < min {
+		max = min
+	}
+	// Double the period for each attempt until it hits the cap
+	d := min
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	// Apply jitter
+	if b.Jitter > 0 && b.Jitter <= 1 {
+		d -= time.Duration(rand.Float64() * b.Jitter * float64(d))
+	}
+	return d
+}
+
+// RetryPolicy sets how Caller retries failed requests. Flood control errors
+// are retried after the period asked by Telegram, while server errors and
+// network failures are retried with exponential backoff. Network failures of
+// a request that might have reached Telegram are only retried for idempotent
+// methods. Requests with files to be uploaded are never retried because the
+// readers cannot be replayed.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    Backoff
+}
+
+// NewRetryPolicy creates new retry policy with default values.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		Backoff: Backoff{
+			Min:    DefaultMinBackoff,
+			Max:    DefaultMaxBackoff,
+			Jitter: DefaultBackoffJitter,
+		},
+	}
+}
+
+// do calls fn and retries it according to the policy.
+func (p *RetryPolicy) do(ctx context.Context, name string, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxRetries {
+			return err
+		}
+		// Check if the error can be retried
+		d, ok := p.delay(name, attempt, err)
+		if !ok || ctx.Err() != nil {
+			return err
+		}
+		if sleepContext(ctx, d) != nil {
+			return err
+		}
+	}
+}
+
+// delay returns the waiting period before retrying a failed request and
+// reports whether the request should be retried at all.
+func (p *RetryPolicy) delay(name string, attempt int, err error) (time.Duration, bool) {
+	// Classify Telegram API error
+	if apierr, ok := asError(err); ok {
+		if apierr.RetryAfter > 0 {
+			return time.Duration(apierr.RetryAfter) * time.Second, true
+		} else if apierr.HTTPCode >= 500 {
+			return p.Backoff.Duration(attempt), true
+		}
+		return 0, false
+	}
+	// Connection failure means the request never reached Telegram
+	var operr *net.OpError
+	if errors.As(err, &operr) && operr.Op == "dial" {
+		return p.Backoff.Duration(attempt), true
+	}
+	// Other network failure is only safe to retry on idempotent method
+	var neterr net.Error
+	if isIdempotent(name) && (errors.As(err, &neterr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)) {
+		return p.Backoff.Duration(attempt), true
+	}
+	return 0, false
+}
+
+// isIdempotent reports whether calling method name more than once has the
+// same effect as calling it once.
+func isIdempotent(name string) bool {
+	switch name {
+	case "setWebhook", "deleteWebhook", "deleteMessage",
+		"banChatMember", "unbanChatMember", "restrictChatMember", "promoteChatMember":
+		return true
+	}
+	return strings.HasPrefix(name, "get")
+}
+
+// sleepContext pauses the current goroutine for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,47 @@
+package telebot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		Backoff:    Backoff{Min: 100 * time.Millisecond, Max: time.Second},
+	}
+	dial := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	read := &net.OpError{Op: "read", Err: errors.New("connection reset")}
+	tests := []struct {
+		name    string
+		method  string
+		attempt int
+		err     error
+		delay   time.Duration
+		ok      bool
+	}{
+		{"retry after", "sendMessage", 0, &Error{HTTPCode: 429, RetryAfter: 3}, 3 * time.Second, true},
+		{"server error", "sendMessage", 1, &Error{HTTPCode: 502}, 200 * time.Millisecond, true},
+		{"server error capped", "sendMessage", 10, &Error{HTTPCode: 500}, time.Second, true},
+		{"client error", "getMe", 0, &Error{HTTPCode: 400, ErrorCode: 400}, 0, false},
+		{"wrapped api error", "sendMessage", 0, fmt.Errorf("call: %w", &Error{RetryAfter: 1}), time.Second, true},
+		{"dial error", "sendMessage", 0, &url.Error{Op: "Post", Err: dial}, 100 * time.Millisecond, true},
+		{"network error idempotent", "getChat", 0, &url.Error{Op: "Post", Err: read}, 100 * time.Millisecond, true},
+		{"network error non-idempotent", "sendMessage", 0, &url.Error{Op: "Post", Err: read}, 0, false},
+		{"unexpected eof idempotent", "deleteMessage", 0, io.ErrUnexpectedEOF, 100 * time.Millisecond, true},
+		{"unexpected eof non-idempotent", "sendMessage", 0, io.ErrUnexpectedEOF, 0, false},
+		{"unclassified idempotent", "getMe", 0, errors.New("unknown"), 0, false},
+		{"unclassified non-idempotent", "sendMessage", 0, errors.New("unknown"), 0, false},
+	}
+	for _, tt := range tests {
+		d, ok := p.delay(tt.method, tt.attempt, tt.err)
+		if d != tt.delay || ok != tt.ok {
+			t.Errorf("%s: delay = %v, %v, want %v, %v", tt.name, d, ok, tt.delay, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package telebot
+
+import "context"
+
+// MessageHandlerFunc handles message payload of an update.
+type MessageHandlerFunc func(ctx context.Context, msg *Message) error
+
+// InlineQueryHandlerFunc handles inline query payload of an update.
+type InlineQueryHandlerFunc func(ctx context.Context, query *InlineQuery) error
+
+// ChosenInlineResultHandlerFunc handles chosen inline result payload of an
+// update.
+type ChosenInlineResultHandlerFunc func(ctx context.Context, result *ChosenInlineResult) error
+
+// CallbackQueryHandlerFunc handles callback query payload of an update.
+type CallbackQueryHandlerFunc func(ctx context.Context, query *CallbackQuery) error
+
+// route represents a handler registered into Router.
+type route struct {
+	match func(upd *Update) bool
+	h     Handler
+}
+
+// Router implements Handler that dispatches updates to the handler registered
+// for their type, so application logic does not depend on whether updates
+// are delivered by polling or webhook. Routes are matched in registration
+// order and the first matching route wins. Updates without any matching route
+// are passed to the fallback handler if set. Middlewares can be attached to
+// every update with Use or to a single route on registration. Register all
+// routes and middlewares before serving updates.
+type Router struct {
+	routes   []*route
+	fallback Handler
+	mws      []Middleware
+	h        Handler
+}
+
+// NewRouter creates new empty router.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Use attaches middlewares to every update passing through the router,
+// including the ones without matching route. The middleware chain is built
+// once here, so stateful middlewares keep their state across updates.
+func (r *Router) Use(mws ...Middleware) {
+	r.mws = append(r.mws, mws...)
+	r.h = Chain(HandlerFunc(r.dispatch), r.mws...)
+}
+
+// Handle registers h for updates of type typ wrapped with route middlewares.
+func (r *Router) Handle(typ UpdateType, h Handler, mws ...Middleware) {
+	r.routes = append(r.routes, &route{
+		match: func(upd *Update) bool {
+			return upd.Type() == typ
+		},
+		h: Chain(h, mws...),
+	})
+}
+
+// HandleFilter registers h for updates accepted by f wrapped with route
+// middlewares.
+func (r *Router) HandleFilter(f Filter, h Handler, mws ...Middleware) {
+	r.routes = append(r.routes, &route{match: f, h: Chain(h, mws...)})
+}
+
+// HandleMessage registers fn for new incoming message.
+func (r *Router) HandleMessage(fn MessageHandlerFunc, mws ...Middleware) {
+	r.Handle(MessageUpdate, HandlerFunc(func(ctx context.Context, upd *Update) error {
+		return fn(ctx, upd.Message)
+	}), mws...)
+}
+
+// HandleEditedMessage registers fn for edited message.
+func (r *Router) HandleEditedMessage(fn MessageHandlerFunc, mws ...Middleware) {
+	r.Handle(EditedMessageUpdate, HandlerFunc(func(ctx context.Context, upd *Update) error {
+		return fn(ctx, upd.EditedMessage)
+	}), mws...)
+}
+
+// HandleChannelPost registers fn for new incoming channel post.
+func (r *Router) HandleChannelPost(fn MessageHandlerFunc, mws ...Middleware) {
+	r.Handle(ChannelPostUpdate, HandlerFunc(func(ctx context.Context, upd *Update) error {
+		return fn(ctx, upd.ChannelPost)
+	}), mws...)
+}
+
+// HandleEditedChannelPost registers fn for edited channel post.
+func (r *Router) HandleEditedChannelPost(fn MessageHandlerFunc, mws ...Middleware) {
+	r.Handle(EditedChannelPostUpdate, HandlerFunc(func(ctx context.Context, upd *Update) error {
+		return fn(ctx, upd.EditedChannelPost)
+	}), mws...)
+}
+
+// HandleInlineQuery registers fn for new incoming inline query.
+func (r *Router) HandleInlineQuery(fn InlineQueryHandlerFunc, mws ...Middleware) {
+	r.Handle(InlineQueryUpdate, HandlerFunc(func(ctx context.Context, upd *Update) error {
+		return fn(ctx, upd.InlineQuery)
+	}), mws...)
+}
+
+// HandleChosenInlineResult registers fn for inline query result chosen by a
+// user.
+func (r *Router) HandleChosenInlineResult(fn ChosenInlineResultHandlerFunc, mws ...Middleware) {
+	r.Handle(ChosenInlineResultUpdate, HandlerFunc(func(ctx context.Context, upd *Update) error {
+		return fn(ctx, upd.ChosenInlineResult)
+	}), mws...)
+}
+
+// HandleCallbackQuery registers fn for new incoming callback query.
+func (r *Router) HandleCallbackQuery(fn CallbackQueryHandlerFunc, mws ...Middleware) {
+	r.Handle(CallbackQueryUpdate, HandlerFunc(func(ctx context.Context, upd *Update) error {
+		return fn(ctx, upd.CallbackQuery)
+	}), mws...)
+}
+
+// HandleFallback registers h for updates without any matching route wrapped
+// with route middlewares.
+func (r *Router) HandleFallback(h Handler, mws ...Middleware) {
+	r.fallback = Chain(h, mws...)
+}
+
+// ServeUpdate implements the Handler interface.
+func (r *Router) ServeUpdate(ctx context.Context, upd *Update) error {
+	if r.h != nil {
+		return r.h.ServeUpdate(ctx, upd)
+	}
+	return r.dispatch(ctx, upd)
+}
+
+// dispatch passes update to the matching route.
+func (r *Router) dispatch(ctx context.Context, upd *Update) error {
+	for _, rt := range r.routes {
+		if rt.match(upd) {
+			return rt.h.ServeUpdate(ctx, upd)
+		}
+	}
+	if r.fallback != nil {
+		return r.fallback.ServeUpdate(ctx, upd)
+	}
+	return nil
+}
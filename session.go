@@ -0,0 +1,266 @@
+package telebot
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultSessionCapacity used to limit the number of sessions in memory.
+const DefaultSessionCapacity = 10000
+
+// SessionStore stores session data by key. Get returns nil value if the key
+// does not exist or has expired. Zero ttl means the value never expires.
+type SessionStore interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// sessionEntry represents a session value with its expiry time.
+type sessionEntry struct {
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// expired reports whether the entry has expired at now.
+func (e *sessionEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// newSessionEntry creates new session entry expiring after ttl.
+func newSessionEntry(key string, value []byte, ttl time.Duration) *sessionEntry {
+	e := &sessionEntry{Key: key, Value: value}
+	if ttl > 0 {
+		e.ExpiresAt = time.Now().Add(ttl)
+	}
+	return e
+}
+
+// MemorySessionStore implements SessionStore in memory. The least recently
+// used session is evicted once the store reaches its capacity.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+// NewMemorySessionStore creates new memory session store holding up to
+// capacity sessions. If capacity is not positive, it will be set to
+// DefaultSessionCapacity.
+func NewMemorySessionStore(capacity int) *MemorySessionStore {
+	if capacity <= 0 {
+		capacity = DefaultSessionCapacity
+	}
+	return &MemorySessionStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Get implements the SessionStore interface.
+func (s *MemorySessionStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	e := el.Value.(*sessionEntry)
+	if e.expired(time.Now()) {
+		s.remove(el)
+		return nil, nil
+	}
+	s.lru.MoveToFront(el)
+	return e.Value, nil
+}
+
+// Set implements the SessionStore interface.
+func (s *MemorySessionStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := newSessionEntry(key, value, ttl)
+	if el, ok := s.entries[key]; ok {
+		el.Value = e
+		s.lru.MoveToFront(el)
+		return nil
+	}
+	s.entries[key] = s.lru.PushFront(e)
+	// Evict the least recently used sessions
+	for s.lru.Len() > s.capacity {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+// Delete implements the SessionStore interface.
+func (s *MemorySessionStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+	return nil
+}
+
+// Len returns the number of sessions in the store.
+func (s *MemorySessionStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// remove removes element from the store. Must be called with lock held.
+func (s *MemorySessionStore) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.entries, el.Value.(*sessionEntry).Key)
+}
+
+// FileSessionStore implements SessionStore with one local file per key inside
+// Dir. Files are replaced atomically on every set and expired files are
+// removed when they are read.
+type FileSessionStore struct {
+	Dir string
+}
+
+// NewFileSessionStore creates new file session store inside dir. The directory
+// is created if it does not exist.
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileSessionStore{Dir: dir}, nil
+}
+
+// path gets the file path of key.
+func (s *FileSessionStore) path(key string) string {
+	return filepath.Join(s.Dir, url.PathEscape(key)+".json")
+}
+
+// Get implements the SessionStore interface.
+func (s *FileSessionStore) Get(key string) ([]byte, error) {
+	buf, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var e sessionEntry
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return nil, err
+	}
+	if e.expired(time.Now()) {
+		return nil, s.Delete(key)
+	}
+	return e.Value, nil
+}
+
+// Set implements the SessionStore interface.
+func (s *FileSessionStore) Set(key string, value []byte, ttl time.Duration) error {
+	buf, err := json.Marshal(newSessionEntry(key, value, ttl))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path(key), func(w io.Writer) error {
+		_, err := w.Write(buf)
+		return err
+	})
+}
+
+// Delete implements the SessionStore interface.
+func (s *FileSessionStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Session represents the session data of a user or a chat. Values are
+// marshalled with JSON.
+type Session struct {
+	Key   string
+	store SessionStore
+	ttl   time.Duration
+}
+
+// Get unmarshals the session data into v. It returns false if the session
+// does not exist.
+func (s *Session) Get(v interface{}) (bool, error) {
+	buf, err := s.store.Get(s.Key)
+	if err != nil || buf == nil {
+		return false, err
+	}
+	return true, json.Unmarshal(buf, v)
+}
+
+// Set marshals v and stores it as the session data.
+func (s *Session) Set(v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(s.Key, buf, s.ttl)
+}
+
+// Delete deletes the session data.
+func (s *Session) Delete() error {
+	return s.store.Delete(s.Key)
+}
+
+// userSessionKey is the context key for user session.
+type userSessionKey struct{}
+
+// chatSessionKey is the context key for chat session.
+type chatSessionKey struct{}
+
+// Sessions creates middleware that attaches the session of the user and the
+// chat of every update to the handler context. Use UserSession and
+// ChatSession to get them. Session data expires after ttl since the last set,
+// or never if ttl is zero.
+func Sessions(store SessionStore, ttl time.Duration) Middleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, upd *Update) error {
+			if from := upd.From(); from != nil {
+				ctx = context.WithValue(ctx, userSessionKey{}, &Session{
+					Key:   "user:" + strconv.FormatInt(from.ID, 10),
+					store: store,
+					ttl:   ttl,
+				})
+			}
+			if chat := upd.Chat(); chat != nil {
+				ctx = context.WithValue(ctx, chatSessionKey{}, &Session{
+					Key:   "chat:" + strconv.FormatInt(chat.ID, 10),
+					store: store,
+					ttl:   ttl,
+				})
+			}
+			return h.ServeUpdate(ctx, upd)
+		})
+	}
+}
+
+// UserSession gets the session of the user sending the update. It returns nil
+// if the update has no sender or ctx does not come from Sessions middleware.
+func UserSession(ctx context.Context) *Session {
+	s, _ := ctx.Value(userSessionKey{}).(*Session)
+	return s
+}
+
+// ChatSession gets the session of the chat of the update. It returns nil if
+// the update has no chat or ctx does not come from Sessions middleware.
+func ChatSession(ctx context.Context) *Session {
+	s, _ := ctx.Value(chatSessionKey{}).(*Session)
+	return s
+}
@@ -0,0 +1,604 @@
+/*
+Package telebottest implements an in-process fake Telegram Bot API server for
+integration tests. It speaks the same JSON envelope as the real API, keeps
+chats and messages in memory, records every call made by the bot and lets
+tests inject updates to be fetched by getUpdates or pushed to the webhook.
+*/
+package telebottest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/adzil/telebot"
+)
+
+// Token is the only bot token accepted by the server.
+const Token = "123456:TEST-TOKEN"
+
+// File represents an uploaded file of a call.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Call represents a method call received by the server. Params holds the
+// request parameters where strings are unquoted and the other values are kept
+// in JSON.
+type Call struct {
+	Method string
+	Params map[string]string
+	Files  map[string]*File
+	Time   time.Time
+}
+
+// Int64 gets parameter name as int64 or zero if it is unset or invalid.
+func (c *Call) Int64(name string) int64 {
+	n, _ := strconv.ParseInt(c.Params[name], 10, 64)
+	return n
+}
+
+// Bool gets parameter name as boolean.
+func (c *Call) Bool(name string) bool {
+	b, _ := strconv.ParseBool(c.Params[name])
+	return b
+}
+
+// Unmarshal decodes JSON parameter name into v.
+func (c *Call) Unmarshal(name string, v interface{}) error {
+	return json.Unmarshal([]byte(c.Params[name]), v)
+}
+
+// HandlerFunc responds to a method call with a result that can be encoded to
+// JSON. Returning a non-nil error replies with the API error envelope, use
+// *telebot.Error to control the error code and response parameters.
+type HandlerFunc func(call *Call) (interface{}, error)
+
+// Server is a fake Telegram Bot API server backed by httptest.Server.
+type Server struct {
+	*httptest.Server
+	Self     telebot.User
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	failures map[string][]error
+	calls    []*Call
+	chats    map[int64]*telebot.Chat
+	messages map[int64][]*telebot.Message
+	updates  []*telebot.Update
+	notify   chan struct{}
+	webhook  telebot.WebhookInfo
+	files    map[string][]byte
+	lastMsg  int64
+	lastUpd  int64
+}
+
+// NewServer starts new fake server. Close it when the test is done.
+func NewServer() *Server {
+	s := &Server{
+		Self: telebot.User{
+			ID:        123456,
+			IsBot:     true,
+			FirstName: "Test",
+			Username:  "test_bot",
+		},
+		failures: make(map[string][]error),
+		chats:    make(map[int64]*telebot.Chat),
+		messages: make(map[int64][]*telebot.Message),
+		files:    make(map[string][]byte),
+		notify:   make(chan struct{}),
+	}
+	s.handlers = map[string]HandlerFunc{
+		"getMe":               s.getMe,
+		"setWebhook":          s.setWebhook,
+		"deleteWebhook":       s.deleteWebhook,
+		"getWebhookInfo":      s.getWebhookInfo,
+		"sendMessage":         s.sendMessage,
+		"editMessageText":     s.editMessageText,
+		"answerCallbackQuery": s.answerCallbackQuery,
+		"deleteMessage":       s.deleteMessage,
+		"getFile":             s.getFile,
+		"getChat":             s.getChat,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Endpoint returns the endpoint URL to be used with telebot.WithEndpoint.
+func (s *Server) Endpoint() string {
+	return s.URL + "/bot"
+}
+
+// Bot creates new bot connected to the server.
+func (s *Server) Bot(opts ...telebot.Option) (*telebot.Bot, error) {
+	opts = append([]telebot.Option{telebot.WithEndpoint(s.Endpoint())}, opts...)
+	return telebot.NewBot(Token, opts...)
+}
+
+// Handle overrides the handler of method name. Use this to script responses of
+// methods that are not implemented by the server.
+func (s *Server) Handle(name string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[name] = fn
+}
+
+// Fail makes the next call of method name returns err.
+func (s *Server) Fail(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[name] = append(s.failures[name], err)
+}
+
+// Calls returns all recorded calls in order.
+func (s *Server) Calls() []*Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Call(nil), s.calls...)
+}
+
+// CallsTo returns all recorded calls of method name in order.
+func (s *Server) CallsTo(name string) []*Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []*Call
+	for _, call := range s.calls {
+		if call.Method == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// AddChat registers chat so the bot can send messages into it.
+func (s *Server) AddChat(chat *telebot.Chat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chats[chat.ID] = chat
+}
+
+// AddFile stores file contents so the bot can get and download it by fileID.
+func (s *Server) AddFile(fileID string, data []byte) *telebot.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[fileID] = data
+	return fileOf(fileID, data)
+}
+
+// fileOf creates file information served for fileID.
+func fileOf(fileID string, data []byte) *telebot.File {
+	return &telebot.File{
+		FileID:       fileID,
+		FileUniqueID: fileID,
+		FileSize:     int64(len(data)),
+		FilePath:     "files/" + fileID,
+	}
+}
+
+// Messages returns all messages of chatID in order. Deleted messages are not
+// included.
+func (s *Server) Messages(chatID int64) []*telebot.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*telebot.Message(nil), s.messages[chatID]...)
+}
+
+// PushUpdate delivers update to the bot. The update is posted to the webhook
+// if it is set, otherwise it is queued for getUpdates. Update identifier is
+// assigned automatically when unset.
+func (s *Server) PushUpdate(upd *telebot.Update) error {
+	s.mu.Lock()
+	if upd.ID == 0 {
+		upd.ID = s.lastUpd + 1
+	}
+	if upd.ID > s.lastUpd {
+		s.lastUpd = upd.ID
+	}
+	url := s.webhook.URL
+	if len(url) == 0 {
+		// Queue update and wake up pending getUpdates
+		s.updates = append(s.updates, upd)
+		close(s.notify)
+		s.notify = make(chan struct{})
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+	// Post update to webhook
+	buf, err := json.Marshal(upd)
+	if err != nil {
+		return err
+	}
+	res, err := http.Post(url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("telebottest: webhook returned %s", res.Status)
+	}
+	return nil
+}
+
+// PushMessage stores a text message from user into chat and delivers it as a
+// message update. Text starting with a slash is marked as a bot command.
+func (s *Server) PushMessage(chat *telebot.Chat, from *telebot.User, text string) (*telebot.Message, error) {
+	s.mu.Lock()
+	if _, ok := s.chats[chat.ID]; !ok {
+		s.chats[chat.ID] = chat
+	}
+	msg := s.newMessage(chat, from, text)
+	if strings.HasPrefix(text, "/") {
+		n := strings.IndexAny(text, " \n")
+		if n < 0 {
+			n = len(text)
+		}
+		msg.Entities = []*telebot.MessageEntity{{
+			Type:   telebot.BotCommandEntity,
+			Length: len(utf16.Encode([]rune(text[:n]))),
+		}}
+	}
+	s.mu.Unlock()
+	return msg, s.PushUpdate(&telebot.Update{Message: msg})
+}
+
+// PushCallbackQuery delivers a callback query update from user pressing an
+// inline keyboard button with data attached to msg.
+func (s *Server) PushCallbackQuery(from *telebot.User, msg *telebot.Message, data string) (*telebot.CallbackQuery, error) {
+	query := &telebot.CallbackQuery{
+		ID:           strconv.FormatInt(time.Now().UnixNano(), 10),
+		From:         from,
+		Message:      msg,
+		ChatInstance: "1",
+		Data:         data,
+	}
+	return query, s.PushUpdate(&telebot.Update{CallbackQuery: query})
+}
+
+// newMessage creates and stores new message. Must be called with lock held.
+func (s *Server) newMessage(chat *telebot.Chat, from *telebot.User, text string) *telebot.Message {
+	s.lastMsg++
+	msg := &telebot.Message{
+		ID:   s.lastMsg,
+		From: from,
+		Date: time.Now().Unix(),
+		Chat: chat,
+		Text: text,
+	}
+	s.messages[chat.ID] = append(s.messages[chat.ID], msg)
+	return msg
+}
+
+// findMessage gets message by chat and message identifier. Must be called with
+// lock held.
+func (s *Server) findMessage(chatID, msgID int64) (int, *telebot.Message) {
+	for i, msg := range s.messages[chatID] {
+		if msg.ID == msgID {
+			return i, msg
+		}
+	}
+	return -1, nil
+}
+
+// serveHTTP dispatches incoming request to the method handler.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/file/bot") {
+		s.serveFile(w, r)
+		return
+	}
+	// Check token and get method name
+	path := strings.TrimPrefix(r.URL.Path, "/bot")
+	n := strings.LastIndex(path, "/")
+	if n < 0 || path[:n] != Token {
+		writeError(w, &telebot.Error{ErrorCode: http.StatusUnauthorized, Description: "Unauthorized"})
+		return
+	}
+	call, err := parseCall(r)
+	if err != nil {
+		writeError(w, &telebot.Error{ErrorCode: http.StatusBadRequest, Description: "Bad Request: " + err.Error()})
+		return
+	}
+	call.Method = path[n+1:]
+	// Record call and look for handler
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	fn, ok := s.handlers[call.Method]
+	if errs := s.failures[call.Method]; len(errs) > 0 {
+		s.failures[call.Method] = errs[1:]
+		s.mu.Unlock()
+		writeError(w, errs[0])
+		return
+	}
+	s.mu.Unlock()
+	// Long polling is handled separately to watch the request context
+	var result interface{}
+	if ok {
+		result, err = fn(call)
+	} else if call.Method == "getUpdates" {
+		result, err = s.getUpdates(r, call)
+	} else {
+		err = &telebot.Error{ErrorCode: http.StatusNotFound, Description: "Not Found"}
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeResult(w, result)
+}
+
+// serveFile serves the contents of file added with AddFile.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/file/bot"+Token+"/")
+	s.mu.Lock()
+	data, ok := s.files[strings.TrimPrefix(path, "files/")]
+	s.mu.Unlock()
+	if !ok || path == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(data)
+}
+
+func (s *Server) getMe(call *Call) (interface{}, error) {
+	return &s.Self, nil
+}
+
+func (s *Server) getUpdates(r *http.Request, call *Call) (interface{}, error) {
+	offset, limit := call.Int64("offset"), int(call.Int64("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	deadline := time.After(time.Duration(call.Int64("timeout")) * time.Second)
+	for {
+		s.mu.Lock()
+		if len(s.webhook.URL) > 0 {
+			s.mu.Unlock()
+			return nil, &telebot.Error{
+				ErrorCode:   http.StatusConflict,
+				Description: "Conflict: can't use getUpdates method while webhook is active",
+			}
+		}
+		// Forget confirmed updates
+		var upds []*telebot.Update
+		for _, upd := range s.updates {
+			if upd.ID >= offset {
+				upds = append(upds, upd)
+			}
+		}
+		s.updates = upds
+		notify := s.notify
+		s.mu.Unlock()
+		if len(upds) > 0 {
+			if len(upds) > limit {
+				upds = upds[:limit]
+			}
+			return upds, nil
+		}
+		// Wait for new update
+		select {
+		case <-notify:
+		case <-deadline:
+			return []*telebot.Update{}, nil
+		case <-r.Context().Done():
+			return []*telebot.Update{}, nil
+		}
+	}
+}
+
+func (s *Server) setWebhook(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhook = telebot.WebhookInfo{
+		URL:                  call.Params["url"],
+		HasCustomCertificate: call.Files["certificate"] != nil,
+		MaxConnections:       int(call.Int64("max_connections")),
+	}
+	if v, ok := call.Params["allowed_updates"]; ok {
+		json.Unmarshal([]byte(v), &s.webhook.AllowedUpdates)
+	}
+	return true, nil
+}
+
+func (s *Server) deleteWebhook(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhook = telebot.WebhookInfo{}
+	return true, nil
+}
+
+func (s *Server) getWebhookInfo(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := s.webhook
+	info.PendingUpdateCount = len(s.updates)
+	return &info, nil
+}
+
+func (s *Server) sendMessage(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chat, ok := s.chats[call.Int64("chat_id")]
+	if !ok {
+		return nil, errChatNotFound
+	}
+	msg := s.newMessage(chat, &s.Self, call.Params["text"])
+	if id := call.Int64("reply_to_message_id"); id != 0 {
+		_, msg.ReplyToMessage = s.findMessage(chat.ID, id)
+	}
+	return msg, nil
+}
+
+func (s *Server) editMessageText(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Inline messages are not stored
+	if len(call.Params["inline_message_id"]) > 0 {
+		return true, nil
+	}
+	_, msg := s.findMessage(call.Int64("chat_id"), call.Int64("message_id"))
+	if msg == nil {
+		return nil, errMessageNotFound
+	}
+	if msg.Text == call.Params["text"] {
+		return nil, &telebot.Error{
+			ErrorCode:   http.StatusBadRequest,
+			Description: "Bad Request: message is not modified",
+		}
+	}
+	msg.Text = call.Params["text"]
+	msg.EditDate = time.Now().Unix()
+	return msg, nil
+}
+
+func (s *Server) answerCallbackQuery(call *Call) (interface{}, error) {
+	return true, nil
+}
+
+func (s *Server) deleteMessage(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chatID := call.Int64("chat_id")
+	i, msg := s.findMessage(chatID, call.Int64("message_id"))
+	if msg == nil {
+		return nil, errMessageNotFound
+	}
+	s.messages[chatID] = append(s.messages[chatID][:i], s.messages[chatID][i+1:]...)
+	return true, nil
+}
+
+func (s *Server) getChat(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chat, ok := s.chats[call.Int64("chat_id")]
+	if !ok {
+		return nil, errChatNotFound
+	}
+	return chat, nil
+}
+
+func (s *Server) getFile(call *Call) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fileID := call.Params["file_id"]
+	data, ok := s.files[fileID]
+	if !ok {
+		return nil, errInvalidFileID
+	}
+	return fileOf(fileID, data), nil
+}
+
+var (
+	errChatNotFound = &telebot.Error{
+		ErrorCode:   http.StatusBadRequest,
+		Description: "Bad Request: chat not found",
+	}
+	errMessageNotFound = &telebot.Error{
+		ErrorCode:   http.StatusBadRequest,
+		Description: "Bad Request: message to edit not found",
+	}
+	errInvalidFileID = &telebot.Error{
+		ErrorCode:   http.StatusBadRequest,
+		Description: "Bad Request: invalid file_id",
+	}
+)
+
+// parseCall reads request parameters from query string, JSON body or form.
+func parseCall(r *http.Request) (*Call, error) {
+	call := &Call{
+		Params: make(map[string]string),
+		Files:  make(map[string]*File),
+		Time:   time.Now(),
+	}
+	for name, values := range r.URL.Query() {
+		call.Params[name] = values[0]
+	}
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "application/json":
+		var fields map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			return nil, err
+		}
+		for name, raw := range fields {
+			value := string(raw)
+			if len(raw) > 0 && raw[0] == '"' {
+				json.Unmarshal(raw, &value)
+			}
+			call.Params[name] = value
+		}
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+		for name, values := range r.MultipartForm.Value {
+			call.Params[name] = values[0]
+		}
+		for name, headers := range r.MultipartForm.File {
+			f, err := headers[0].Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := ioutil.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			call.Files[name] = &File{Name: headers[0].Filename, Data: data}
+		}
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		for name, values := range r.PostForm {
+			call.Params[name] = values[0]
+		}
+	}
+	return call, nil
+}
+
+// writeResult writes successful response envelope.
+func writeResult(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":     true,
+		"result": result,
+	})
+}
+
+// writeError writes error response envelope.
+func writeError(w http.ResponseWriter, err error) {
+	apierr, ok := err.(*telebot.Error)
+	if !ok {
+		apierr = &telebot.Error{
+			ErrorCode:   http.StatusInternalServerError,
+			Description: err.Error(),
+		}
+	}
+	code := apierr.ErrorCode
+	if code == 0 {
+		code = http.StatusBadRequest
+	}
+	res := map[string]interface{}{
+		"ok":          false,
+		"error_code":  code,
+		"description": apierr.Description,
+	}
+	if apierr.RetryAfter != 0 || apierr.MigrateToChatID != 0 {
+		res["parameters"] = &telebot.ResponseParameters{
+			RetryAfter:      apierr.RetryAfter,
+			MigrateToChatID: apierr.MigrateToChatID,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(res)
+}
@@ -71,6 +71,45 @@ func (u *Update) Type() UpdateType {
 	return ""
 }
 
+// Chat gets the chat where the update comes from. It returns nil for inline
+// query, chosen inline result and callback query from inline message.
+func (u *Update) Chat() *Chat {
+	switch {
+	case u.Message != nil:
+		return u.Message.Chat
+	case u.EditedMessage != nil:
+		return u.EditedMessage.Chat
+	case u.ChannelPost != nil:
+		return u.ChannelPost.Chat
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost.Chat
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+		return u.CallbackQuery.Message.Chat
+	}
+	return nil
+}
+
+// From gets the user who sends the update. It returns nil for channel post.
+func (u *Update) From() *User {
+	switch {
+	case u.Message != nil:
+		return u.Message.From
+	case u.EditedMessage != nil:
+		return u.EditedMessage.From
+	case u.ChannelPost != nil:
+		return u.ChannelPost.From
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost.From
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From
+	case u.ChosenInlineResult != nil:
+		return u.ChosenInlineResult.From
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.From
+	}
+	return nil
+}
+
 // WebhookInfo contains information about the current status of a webhook.
 type WebhookInfo struct {
 	URL                  string   `json:"url"`
@@ -111,16 +150,17 @@ const (
 
 // Chat represents a chat.
 type Chat struct {
-	ID                          int64    `json:"id"`
-	Type                        ChatType `json:"type"`
-	Title                       string   `json:"title,omitempty"`
-	Username                    string   `json:"username,omitempty"`
-	FirstName                   string   `json:"first_name,omitempty"`
-	LastName                    string   `json:"last_name,omitempty"`
-	AllMembersAreAdministrators bool     `json:"all_members_are_administrators,omitempty"`
-	Description                 string   `json:"description,omitempty"`
-	InviteLink                  string   `json:"invite_link,omitempty"`
-	PinnedMessage               *Message `json:"pinned_message,omitempty"`
+	ID                          int64            `json:"id"`
+	Type                        ChatType         `json:"type"`
+	Title                       string           `json:"title,omitempty"`
+	Username                    string           `json:"username,omitempty"`
+	FirstName                   string           `json:"first_name,omitempty"`
+	LastName                    string           `json:"last_name,omitempty"`
+	AllMembersAreAdministrators bool             `json:"all_members_are_administrators,omitempty"`
+	Description                 string           `json:"description,omitempty"`
+	InviteLink                  string           `json:"invite_link,omitempty"`
+	PinnedMessage               *Message         `json:"pinned_message,omitempty"`
+	Permissions                 *ChatPermissions `json:"permissions,omitempty"`
 }
 
 // Message represents a message.
@@ -141,6 +181,14 @@ type Message struct {
 	Text                 string           `json:"text,omitempty"`
 	Entities             []*MessageEntity `json:"entities,omitempty"`
 	CaptionEntities      []*MessageEntity `json:"caption_entities,omitempty"`
+	Audio                *Audio           `json:"audio,omitempty"`
+	Document             *Document        `json:"document,omitempty"`
+	Animation            *Animation       `json:"animation,omitempty"`
+	Photo                []*PhotoSize     `json:"photo,omitempty"`
+	Sticker              *Sticker         `json:"sticker,omitempty"`
+	Video                *Video           `json:"video,omitempty"`
+	VideoNote            *VideoNote       `json:"video_note,omitempty"`
+	Voice                *Voice           `json:"voice,omitempty"`
 	Caption              string           `json:"caption,omitempty"`
 	Contact              *Contact         `json:"contact,omitempty"`
 	Location             *Location        `json:"location,omitempty"`
@@ -152,6 +200,41 @@ type Message struct {
 	ConnectedWebsite     string           `json:"connected_website,omitempty"`
 }
 
+// ContentType gets the message content type. It returns empty string for
+// service messages.
+func (m *Message) ContentType() MessageContentType {
+	switch {
+	case len(m.Text) > 0:
+		return TextMessage
+	case m.Animation != nil:
+		// Animation message also sets document for backward compatibility
+		return AnimationMessage
+	case m.Audio != nil:
+		return AudioMessage
+	case m.Document != nil:
+		return DocumentMessage
+	case len(m.Photo) > 0:
+		return PhotoMessage
+	case m.Sticker != nil:
+		return StickerMessage
+	case m.Video != nil:
+		return VideoMessage
+	case m.VideoNote != nil:
+		return VideoNoteMessage
+	case m.Voice != nil:
+		return VoiceMessage
+	case m.Contact != nil:
+		return ContactMessage
+	case m.Venue != nil:
+		// Venue message also sets location
+		return VenueMessage
+	case m.Location != nil:
+		return LocationMessage
+	}
+	// Cannot determine the content type
+	return ""
+}
+
 // MessageEntityType represents the message entity type.
 type MessageEntityType string
 
@@ -222,6 +305,96 @@ type Venue struct {
 	FoursquareID string    `json:"foursquare_id,omitempty"`
 }
 
+// PhotoSize represents one size of a photo or a file or sticker thumbnail.
+type PhotoSize struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// Audio represents an audio file to be treated as music by the Telegram
+// clients.
+type Audio struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Duration     int        `json:"duration"`
+	Performer    string     `json:"performer,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+	Thumb        *PhotoSize `json:"thumb,omitempty"`
+}
+
+// Document represents a general file.
+type Document struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Thumb        *PhotoSize `json:"thumb,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
+// Video represents a video file.
+type Video struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	Duration     int        `json:"duration"`
+	Thumb        *PhotoSize `json:"thumb,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
+// Animation represents an animation file (GIF or H.264/MPEG-4 AVC video
+// without sound).
+type Animation struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	Duration     int        `json:"duration"`
+	Thumb        *PhotoSize `json:"thumb,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
+// Voice represents a voice note.
+type Voice struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Duration     int    `json:"duration"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// VideoNote represents a video message.
+type VideoNote struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Length       int        `json:"length"`
+	Duration     int        `json:"duration"`
+	Thumb        *PhotoSize `json:"thumb,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
+// Sticker represents a sticker.
+type Sticker struct {
+	FileID       string     `json:"file_id"`
+	FileUniqueID string     `json:"file_unique_id"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	IsAnimated   bool       `json:"is_animated"`
+	Thumb        *PhotoSize `json:"thumb,omitempty"`
+	Emoji        string     `json:"emoji,omitempty"`
+	SetName      string     `json:"set_name,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
 // ReplyKeyboardMarkup represents a custom keyboard with reply options.
 type ReplyKeyboardMarkup struct {
 	Keyboard        [][]*KeyboardButton `json:"keyboard"`
@@ -388,6 +561,19 @@ type ChatMember struct {
 	CanAddWebPagePreviews bool             `json:"can_add_web_page_previews,omitempty"`
 }
 
+// ChatPermissions describes actions that a non-administrator user is allowed
+// to take in a chat.
+type ChatPermissions struct {
+	CanSendMessages       bool `json:"can_send_messages,omitempty"`
+	CanSendMediaMessages  bool `json:"can_send_media_messages,omitempty"`
+	CanSendPolls          bool `json:"can_send_polls,omitempty"`
+	CanSendOtherMessages  bool `json:"can_send_other_messages,omitempty"`
+	CanAddWebPagePreviews bool `json:"can_add_web_page_previews,omitempty"`
+	CanChangeInfo         bool `json:"can_change_info,omitempty"`
+	CanInviteUsers        bool `json:"can_invite_users,omitempty"`
+	CanPinMessages        bool `json:"can_pin_messages,omitempty"`
+}
+
 // ResponseParameters contains information about why a request was unsuccessful.
 type ResponseParameters struct {
 	MigrateToChatID int64 `json:"migrate_to_chat_id,omitempty"`
@@ -0,0 +1,152 @@
+package telebot
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShutdownTimeout used to wait for webhook server graceful shutdown.
+	DefaultShutdownTimeout = 5 * time.Second
+
+	// MaxWebhookBodySize used to limit the size of webhook request body.
+	MaxWebhookBodySize = 1 << 20
+)
+
+// webhookHandler implements http.Handler to receive updates from webhook. The
+// ctx is the server lifetime context.
+type webhookHandler struct {
+	bot *Bot
+	h   Handler
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (wh *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Track active handler so the server can wait for it on shutdown
+	wh.wg.Add(1)
+	defer wh.wg.Done()
+	// Telegram only sends updates with POST method
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	// Decode incoming update with limited body size
+	var upd Update
+	body := http.MaxBytesReader(w, r.Body, MaxWebhookBodySize)
+	if err := json.NewDecoder(body).Decode(&upd); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	// Pass update to handler
+	if err := wh.h.ServeUpdate(r.Context(), &upd); err != nil {
+		// Ask Telegram to redeliver update aborted by the request or server
+		// cancellation, but not the one failed by the handler itself
+		if r.Context().Err() != nil || wh.ctx.Err() != nil {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		reportError(wh.bot.ErrorHandler, err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// WebhookHandler creates http.Handler that decodes JSON-serialized Update
+// object sent by the webhook and passes it to h. Use this handler to mount
+// webhook on your own HTTP server, otherwise use ServeWebhook method instead.
+func (b *Bot) WebhookHandler(h Handler) http.Handler {
+	return &webhookHandler{bot: b, h: h, ctx: context.Background()}
+}
+
+// ServeWebhook registers webhook with SetWebhook method and serves incoming
+// updates at addr by calling h. The handler is mounted on the path of the
+// webhook URL, so use it as a secret path if possible. The server only speaks
+// plain HTTP, so TLS must be terminated by a reverse proxy in front of it. It
+// blocks until ctx is cancelled, then removes the webhook, shuts down the
+// server and returns the context error.
+func (b *Bot) ServeWebhook(ctx context.Context, addr string, req *SetWebhook, h Handler) error {
+	// Use webhook URL path as the handler path
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+	path := u.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+	handler := &webhookHandler{bot: b, h: h, ctx: ctx}
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	// Listen before registering webhook so it can receive updates
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if err = b.SetWebhookContext(ctx, req); err != nil {
+		ln.Close()
+		return err
+	}
+	// Serve webhook in background
+	srv := &http.Server{Handler: mux}
+	srverr := make(chan error, 1)
+	go func() {
+		srverr <- srv.Serve(ln)
+	}()
+	// Wait until cancellation or server failure
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-srverr:
+	}
+	// Remove webhook and shutdown server within shutdown timeout
+	sctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+	if _, derr := b.DeleteWebhookContext(sctx); derr != nil {
+		reportError(b.ErrorHandler, derr)
+	}
+	if srv.Shutdown(sctx) != nil {
+		srv.Close()
+	}
+	// Wait for active handlers before returning
+	handler.wg.Wait()
+	return err
+}
+
+// StartWebhook is like ServeWebhook but passes incoming updates into channel.
+// The channel will be closed once ctx is cancelled. Webhook errors are passed
+// to ErrorHandler, so they never block the update delivery. To stop the server
+// and remove the webhook, pass a cancellation context into ctx. Otherwise, use
+// nil or background context.
+func (b *Bot) StartWebhook(ctx context.Context, addr string, req *SetWebhook) <-chan *Update {
+	// Fill context with background if nil
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Create goroutine to enable asynchronous update mechanism
+	retupd := make(chan *Update)
+	go func() {
+		defer close(retupd)
+		// Stop delivery on cancellation regardless of the request context
+		h := HandlerFunc(func(rctx context.Context, upd *Update) error {
+			select {
+			case retupd <- upd:
+				return nil
+			case <-rctx.Done():
+				return rctx.Err()
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err := b.ServeWebhook(ctx, addr, req, h); err != nil && ctx.Err() == nil {
+			reportError(b.ErrorHandler, err)
+		}
+	}()
+	return retupd
+}
@@ -0,0 +1,153 @@
+package telebot_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adzil/telebot"
+	"github.com/adzil/telebot/telebottest"
+)
+
+// freeAddr returns local address with an unused port.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// waitWebhook waits until the webhook is registered on the server.
+func waitWebhook(t *testing.T, bot *telebot.Bot) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := bot.GetWebhookInfo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(info.URL) > 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("webhook was not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeWebhook(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := freeAddr(t)
+	texts := make(chan string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- bot.ServeWebhook(ctx, addr, &telebot.SetWebhook{URL: "http://" + addr + "/hook"},
+			telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+				texts <- upd.Message.Text
+				return nil
+			}))
+	}()
+	waitWebhook(t, bot)
+	if _, err := srv.PushMessage(&telebot.Chat{ID: 1}, &telebot.User{ID: 1}, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if text := <-texts; text != "hello" {
+		t.Fatalf("got text %q, want %q", text, "hello")
+	}
+	// Webhook is removed on shutdown
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("ServeWebhook returned %v, want %v", err, context.Canceled)
+	}
+	if n := len(srv.CallsTo("deleteWebhook")); n != 1 {
+		t.Fatalf("got %d deleteWebhook calls, want 1", n)
+	}
+}
+
+func TestStartWebhook(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	upds := bot.StartWebhook(ctx, addr, &telebot.SetWebhook{URL: "http://" + addr + "/"})
+	waitWebhook(t, bot)
+	go srv.PushMessage(&telebot.Chat{ID: 1}, &telebot.User{ID: 1}, "hello")
+	if upd := <-upds; upd.Message.Text != "hello" {
+		t.Fatalf("got text %q, want %q", upd.Message.Text, "hello")
+	}
+	// Channel is closed once the webhook is removed
+	cancel()
+	for range upds {
+	}
+	if n := len(srv.CallsTo("deleteWebhook")); n != 1 {
+		t.Fatalf("got %d deleteWebhook calls, want 1", n)
+	}
+}
+
+func TestWebhookHandlerStatus(t *testing.T) {
+	srv := telebottest.NewServer()
+	defer srv.Close()
+	bot, err := srv.Bot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reported []error
+	bot.ErrorHandler = func(err error) {
+		reported = append(reported, err)
+	}
+	errHandler := errors.New("handler failed")
+	wh := bot.WebhookHandler(telebot.HandlerFunc(func(ctx context.Context, upd *telebot.Update) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errHandler
+	}))
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		ctx    context.Context
+		status int
+	}{
+		{"handler error", http.MethodPost, `{"update_id":1}`, context.Background(), http.StatusOK},
+		{"cancelled request", http.MethodPost, `{"update_id":1}`, cancelled, http.StatusServiceUnavailable},
+		{"invalid body", http.MethodPost, `{`, context.Background(), http.StatusBadRequest},
+		{"body too large", http.MethodPost, `{"update_id":1,"x":"` + strings.Repeat("x", telebot.MaxWebhookBodySize) + `"}`, context.Background(), http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "", context.Background(), http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body)).WithContext(tt.ctx)
+		w := httptest.NewRecorder()
+		wh.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+	// Only the handler failure is reported instead of redelivered
+	if len(reported) != 1 || reported[0] != errHandler {
+		t.Fatalf("reported errors %v, want [%v]", reported, errHandler)
+	}
+}